@@ -0,0 +1,96 @@
+package brokerapi
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/pivotal-golang/lager"
+)
+
+const requestLogKey = "request"
+const readRequestBodyErrorKey = "read-request-body"
+const redactedValue = "REDACTED"
+const maxRequestBodySize = 1 << 20
+
+var errRequestBodyTooLarge = errors.New("request body too large")
+
+var DefaultAccessLogLevels = map[string]lager.LogLevel{
+	"GET":    lager.DEBUG,
+	"PUT":    lager.INFO,
+	"POST":   lager.INFO,
+	"PATCH":  lager.INFO,
+	"DELETE": lager.INFO,
+}
+
+func wrapAccessLog(handler http.Handler, logger lager.Logger, options options) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		data := lager.Data{
+			"method": req.Method,
+			"path":   req.URL.Path,
+		}
+
+		if options.queryLogging && req.URL.RawQuery != "" {
+			data["query"] = redactQuery(req.URL.Query(), options.redactedQueryParams)
+		}
+
+		if options.platformDetection {
+			data["platform"] = PlatformFromRequest(req)
+		}
+
+		if options.bodyHashLogging {
+			body, err := ioutil.ReadAll(io.LimitReader(req.Body, maxRequestBodySize+1))
+			if err != nil {
+				logger.Error(readRequestBodyErrorKey, err, data)
+				respond(w, http.StatusBadRequest, ErrorResponse{
+					Description: err.Error(),
+				})
+				return
+			}
+			if len(body) > maxRequestBodySize {
+				logger.Error(readRequestBodyErrorKey, errRequestBodyTooLarge, data)
+				respond(w, http.StatusRequestEntityTooLarge, ErrorResponse{
+					Description: errRequestBodyTooLarge.Error(),
+				})
+				return
+			}
+			req.Body.Close()
+			req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+			sum := sha256.Sum256(body)
+			data["body-sha256"] = hex.EncodeToString(sum[:])
+		}
+
+		level, ok := options.accessLogLevels[req.Method]
+		if !ok {
+			level = lager.INFO
+		}
+
+		switch level {
+		case lager.DEBUG:
+			logger.Debug(requestLogKey, data)
+		case lager.ERROR:
+			logger.Error(requestLogKey, nil, data)
+		default:
+			logger.Info(requestLogKey, data)
+		}
+
+		handler.ServeHTTP(w, req)
+	})
+}
+
+func redactQuery(query url.Values, redactedParams []string) string {
+	for _, param := range redactedParams {
+		if values, found := query[param]; found {
+			for i := range values {
+				values[i] = redactedValue
+			}
+		}
+	}
+	return query.Encode()
+}
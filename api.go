@@ -2,7 +2,12 @@ package brokerapi
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/pivotal-cf/brokerapi/auth"
 	"github.com/pivotal-golang/lager"
@@ -16,51 +21,312 @@ const unbindLogKey = "unbind"
 const instanceIDLogKey = "instance-id"
 const instanceDetailsLogKey = "instance-details"
 const bindingIDLogKey = "binding-id"
+const serviceIDLogKey = "service-id"
+const planIDLogKey = "plan-id"
+const planNameLogKey = "plan-name"
 
 const invalidServiceDetailsErrorKey = "invalid-service-details"
+const invalidBindDetailsErrorKey = "invalid-bind-details"
 const instanceLimitReachedErrorKey = "instance-limit-reached"
 const instanceAlreadyExistsErrorKey = "instance-already-exists"
 const bindingAlreadyExistsErrorKey = "binding-already-exists"
 const instanceMissingErrorKey = "instance-missing"
 const bindingMissingErrorKey = "binding-missing"
 const unknownErrorKey = "unknown-error"
+const serviceUnavailableErrorKey = "service-unavailable"
+const concurrentOperationErrorKey = "concurrent-operation"
+const deprecatedPlanErrorKey = "deprecated-plan"
+const resourceQuotaExceededErrorKey = "resource-quota-exceeded"
+const quotaExceededErrorKey = "quota-exceeded"
+const invalidCatalogErrorKey = "invalid-catalog"
+const invalidConfigurationErrorKey = "invalid-configuration"
+const credentialsErrorKey = "credentials-failed"
 
 const statusUnprocessableEntity = 422
 
+const serviceUnavailableRetryAfterSeconds = "30"
+
 type BrokerCredentials struct {
 	Username string
 	Password string
 }
 
-func New(serviceBroker ServiceBroker, logger lager.Logger, brokerCredentials BrokerCredentials) http.Handler {
+func New(serviceBroker ServiceBroker, logger lager.Logger, brokerCredentials BrokerCredentials, opts ...Option) http.Handler {
+	options := newOptions(opts)
+
+	if err := validateOptions(options); err != nil {
+		logger.Error(invalidConfigurationErrorKey, err)
+	}
+
+	if options.validateCatalog {
+		if err := ValidateCatalog(serviceBroker.Services()); err != nil {
+			logger.Error(invalidCatalogErrorKey, err)
+		}
+	}
+
+	return newHandler(serviceBroker, logger, brokerCredentials, options)
+}
+
+func NewHandler(serviceBroker ServiceBroker, logger lager.Logger, brokerCredentials BrokerCredentials, opts ...Option) (http.Handler, error) {
+	options := newOptions(opts)
+
+	if err := validateOptions(options); err != nil {
+		return nil, err
+	}
+
+	if options.validateCatalog {
+		if err := ValidateCatalog(serviceBroker.Services()); err != nil {
+			return nil, err
+		}
+	}
+
+	return newHandler(serviceBroker, logger, brokerCredentials, options), nil
+}
+
+func validateOptions(options options) error {
+	if options.instanceLimits {
+		if _, ok := options.store.(InstanceReserver); !ok {
+			return errInstanceLimitsWithoutReserver
+		}
+	}
+
+	for method, level := range options.accessLogLevels {
+		if level != lager.DEBUG && level != lager.INFO && level != lager.ERROR {
+			return fmt.Errorf("unsupported access log level %d for %s requests", level, method)
+		}
+	}
+	return nil
+}
+
+func newHandler(serviceBroker ServiceBroker, logger lager.Logger, brokerCredentials BrokerCredentials, options options) http.Handler {
+	restorableBroker, _ := serviceBroker.(RestorableServiceBroker)
+	taggableBroker, _ := serviceBroker.(TaggableServiceBroker)
+	catalogProber, _ := serviceBroker.(CatalogProber)
+
+	for _, wrap := range options.brokerWrappers {
+		serviceBroker = wrap(serviceBroker)
+	}
+
+	if options.store != nil {
+		serviceBroker = newStoreBroker(serviceBroker, options.store)
+	}
+
 	router := newHttpRouter()
 
-	router.Get("/v2/catalog", catalog(serviceBroker, router, logger))
+	router.Get("/v2/catalog", catalog(serviceBroker, router, logger, options))
+
+	if options.extendedEndpoints {
+		router.Get("/v2/service_plans", servicePlans(serviceBroker, router, logger, options))
+	}
+
+	if options.planRecommender != nil {
+		router.Post("/v2/service_plans/recommend", recommend(options.planRecommender, router, logger))
+	}
+
+	if options.pricingCalculator != nil {
+		router.Get("/v2/service_plans/{plan_id}/pricing", pricing(serviceBroker, options.pricingCalculator, router, logger))
+	}
+
+	if options.planStateStore != nil {
+		router.Put("/admin/service_plans/{plan_id}/enable", setPlanState(serviceBroker, options.planStateStore, true, router, logger))
+		router.Put("/admin/service_plans/{plan_id}/disable", setPlanState(serviceBroker, options.planStateStore, false, router, logger))
+	}
+
+	if options.eventLog != nil {
+		router.Get("/admin/service_instances/{instance_id}/events", events(options.eventLog, router, logger))
+	}
+
+	operation := operationWrapper(router, logger, options)
+	notifier := newEventNotifier(options, logger)
+
+	router.Put("/v2/service_instances/{instance_id}", operation(provisionLogKey, provision(serviceBroker, router, logger, options, notifier)))
+	router.Delete("/v2/service_instances/{instance_id}", operation(deprovisionLogKey, deprovision(serviceBroker, router, logger, options, notifier)))
+
+	if options.restoreSupport {
+		router.Post("/v2/service_instances/{instance_id}/restore", operation(restoreLogKey, restore(restorableBroker, router, logger, options)))
+	}
+
+	if options.taggingSupport {
+		router.Post("/v2/service_instances/{instance_id}/tags", operation(tagLogKey, tag(taggableBroker, router, logger, options)))
+		router.Delete("/v2/service_instances/{instance_id}/tags/{key}", operation(untagLogKey, untag(taggableBroker, router, logger, options)))
+	}
 
-	router.Put("/v2/service_instances/{instance_id}", provision(serviceBroker, router, logger))
-	router.Delete("/v2/service_instances/{instance_id}", deprovision(serviceBroker, router, logger))
+	router.Put("/v2/service_instances/{instance_id}/service_bindings/{binding_id}", operation(bindLogKey, bind(serviceBroker, router, logger, options, notifier)))
+	router.Delete("/v2/service_instances/{instance_id}/service_bindings/{binding_id}", operation(unbindLogKey, unbind(serviceBroker, router, logger, options)))
 
-	router.Put("/v2/service_instances/{instance_id}/service_bindings/{binding_id}", bind(serviceBroker, router, logger))
-	router.Delete("/v2/service_instances/{instance_id}/service_bindings/{binding_id}", unbind(serviceBroker, router, logger))
+	if options.answerOptions {
+		router.AnswerOptions()
+	}
+
+	if options.capabilitiesEndpoint {
+		router.Options("/v2", capabilities(options))
+	}
+
+	var handler http.Handler = router
+	if options.accessLogEnabled() {
+		handler = wrapAccessLog(handler, logger, options)
+	}
+
+	if options.platformDetection {
+		handler = wrapPlatformDetection(handler)
+	}
+
+	handler = wrapAuth(handler, brokerCredentials)
 
-	return wrapAuth(router, brokerCredentials)
+	if catalogProber != nil && options.catalogProbeHeader != "" {
+		handler = wrapCatalogProbe(handler, catalogProber, options.catalogProbeHeader, logger)
+	}
+
+	if options.headerAudit {
+		handler = wrapHeaderAudit(handler, options.maxHeaderBytes, logger)
+	}
+
+	if options.cors != nil {
+		handler = wrapCORS(handler, *options.cors)
+	}
+
+	if options.keepAliveTimeout > 0 {
+		handler = wrapKeepAlive(handler, options.keepAliveTimeout)
+	}
+
+	handler = wrapAPIVersion(handler, options.apiVersion)
+
+	if options.errorEnvelope != nil {
+		handler = wrapErrorEnvelope(handler, options.errorEnvelope)
+	}
+
+	if options.commonLogWriter != nil {
+		handler = wrapCommonLog(handler, options.commonLogWriter)
+	}
+
+	return handler
 }
 
-func wrapAuth(router httpRouter, credentials BrokerCredentials) http.Handler {
-	return auth.NewWrapper(credentials.Username, credentials.Password).Wrap(router)
+func operationWrapper(router httpRouter, logger lager.Logger, options options) func(string, http.HandlerFunc) http.HandlerFunc {
+	locks := newInstanceLocks()
+
+	return func(operation string, handler http.HandlerFunc) http.HandlerFunc {
+		if options.instanceLocking {
+			handler = lockInstance(handler, locks, router, logger)
+		}
+		if options.requireOriginatingIdentity {
+			handler = requireOriginatingIdentity(handler, logger)
+		}
+		if options.readOnlyMode != nil {
+			handler = rejectWhenReadOnly(handler, options.readOnlyMode, logger)
+		}
+		if options.eventLog != nil {
+			handler = recordEvents(options.eventLog, operation, router, logger, handler)
+		}
+		return handler
+	}
 }
 
-func catalog(serviceBroker ServiceBroker, router httpRouter, logger lager.Logger) http.HandlerFunc {
+func wrapAuth(handler http.Handler, credentials BrokerCredentials) http.Handler {
+	return auth.NewWrapper(credentials.Username, credentials.Password).Wrap(handler)
+}
+
+func catalog(serviceBroker ServiceBroker, router httpRouter, logger lager.Logger, options options) http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
+		services := catalogServices(serviceBroker, options)
+
+		if filter := req.URL.Query().Get("filter"); options.catalogFiltering && filter != "" {
+			matches, err := parseCatalogFilter(filter)
+			if err != nil {
+				logger.Error(invalidCatalogFilterErrorKey, err)
+				respond(w, http.StatusBadRequest, ErrorResponse{
+					Description: err.Error(),
+				})
+				return
+			}
+			services = filterServices(services, matches)
+		}
+
+		if options.streamingCatalog && !options.indentCatalog {
+			respondStreamingCatalog(w, services, logger)
+			return
+		}
+
 		catalog := CatalogResponse{
-			Services: serviceBroker.Services(),
+			Services: services,
+		}
+
+		if options.indentCatalog {
+			respondIndented(w, http.StatusOK, catalog)
+			return
 		}
 
 		respond(w, http.StatusOK, catalog)
 	}
 }
 
-func provision(serviceBroker ServiceBroker, router httpRouter, logger lager.Logger) http.HandlerFunc {
+func servicePlans(serviceBroker ServiceBroker, router httpRouter, logger lager.Logger, options options) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		serviceID := req.URL.Query().Get("service_id")
+
+		offset, limit, err := parsePaginationQuery(req)
+		if err != nil {
+			respond(w, http.StatusBadRequest, ErrorResponse{
+				Description: err.Error(),
+			})
+			return
+		}
+
+		plans := []ServicePlan{}
+		serviceFound := false
+		for _, service := range catalogServices(serviceBroker, options) {
+			if serviceID != "" && service.ID != serviceID {
+				continue
+			}
+
+			serviceFound = true
+			plans = append(plans, service.Plans...)
+		}
+
+		if serviceID != "" && !serviceFound {
+			respond(w, http.StatusNotFound, ErrorResponse{
+				Description: "service does not exist",
+			})
+			return
+		}
+
+		respond(w, http.StatusOK, newServicePlanListResponse(plans, offset, limit))
+	}
+}
+
+func parsePaginationQuery(req *http.Request) (int, int, error) {
+	query := req.URL.Query()
+
+	offset := 0
+	if rawOffset := query.Get("offset"); rawOffset != "" {
+		parsed, err := strconv.Atoi(rawOffset)
+		if err != nil || parsed < 0 {
+			return 0, 0, fmt.Errorf("offset must be a non-negative integer, got %q", rawOffset)
+		}
+		offset = parsed
+	}
+
+	limit := 0
+	if rawLimit := query.Get("limit"); rawLimit != "" {
+		parsed, err := strconv.Atoi(rawLimit)
+		if err != nil || parsed < 1 {
+			return 0, 0, fmt.Errorf("limit must be a positive integer, got %q", rawLimit)
+		}
+		limit = parsed
+	}
+
+	return offset, limit, nil
+}
+
+func catalogServices(serviceBroker ServiceBroker, options options) []Service {
+	services := serviceBroker.Services()
+	if options.planHealthy != nil {
+		services = healthyServices(services, options.planHealthy)
+	}
+	return services
+}
+
+func provision(serviceBroker ServiceBroker, router httpRouter, logger lager.Logger, options options, notifier *eventNotifier) http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
 		vars := router.Vars(req)
 		instanceID := vars["instance_id"]
@@ -78,22 +344,146 @@ func provision(serviceBroker ServiceBroker, router httpRouter, logger lager.Logg
 			return
 		}
 
-		logger = logger.WithData(lager.Data{
+		catalog := newRequestCatalog(serviceBroker)
+		logger = withServiceAndPlanIDs(logger, catalog, options, serviceDetails.ID, serviceDetails.PlanID).WithData(lager.Data{
 			instanceDetailsLogKey: serviceDetails,
 		})
 
+		if options.store != nil {
+			if _, err := options.store.FindInstance(instanceID); err == nil {
+				logBrokerError(logger, options.errorLogLevels, instanceAlreadyExistsErrorKey, ErrInstanceAlreadyExists)
+				respond(w, http.StatusConflict, EmptyResponse{})
+				return
+			}
+		}
+
+		if err := checkParameterDepth(serviceDetails.Parameters, options.maxParameterDepth); err != nil {
+			logger.Error(parametersTooDeepErrorKey, err)
+			respond(w, http.StatusBadRequest, ErrorResponse{
+				Description: err.Error(),
+			})
+			return
+		}
+
+		if err := validateContext(serviceDetails.Context, options.contextValidators); err != nil {
+			logger.Error(invalidContextErrorKey, err)
+			respond(w, http.StatusBadRequest, ErrorResponse{
+				Description: err.Error(),
+			})
+			return
+		}
+
+		if options.rejectDeprecatedPlanProvisioning {
+			plan, found := findPlan(catalog.Services(), serviceDetails.PlanID)
+			if found && plan.Metadata.Deprecated {
+				err := deprecatedPlanError(plan)
+				logger.Error(deprecatedPlanErrorKey, err)
+				respond(w, statusUnprocessableEntity, ErrorResponse{
+					Description: err.Error(),
+				})
+				return
+			}
+		}
+
+		if options.deprecationSchedule {
+			if plan, found := findPlan(catalog.Services(), serviceDetails.PlanID); found {
+				now := options.clock.Now()
+				if planRemoved(plan, now) {
+					logger.Error(planRemovedErrorKey, errPlanRemoved)
+					respond(w, statusUnprocessableEntity, ErrorResponse{
+						Description: errPlanRemoved.Error(),
+					})
+					return
+				}
+				if planPastDeprecation(plan, now) {
+					logger.Info(planPastDeprecationLogKey, lager.Data{
+						"removal-date": plan.RemovalDate,
+					})
+				}
+			}
+		}
+
+		if !planEnabled(catalog.Services(), serviceDetails.PlanID, options.planStateStore) {
+			logger.Error(planDisabledErrorKey, errPlanDisabled)
+			respond(w, http.StatusBadRequest, ErrorResponse{
+				Description: errPlanDisabled.Error(),
+			})
+			return
+		}
+
+		if reserver, ok := options.store.(InstanceReserver); ok && options.instanceLimits {
+			reserved, err := reserveInstance(catalog.Services(), instanceID, serviceDetails.ID, reserver)
+			if err != nil {
+				if err == ErrMaximumInstancesReached {
+					logger.Error(maximumInstancesReachedErrorKey, err)
+					respond(w, statusUnprocessableEntity, ErrorResponse{
+						Description: err.Error(),
+					})
+				} else {
+					logger.Error(unknownErrorKey, err)
+					respond(w, http.StatusInternalServerError, ErrorResponse{
+						Description: err.Error(),
+					})
+				}
+				return
+			}
+			if reserved {
+				defer reserver.ReleaseInstance(instanceID)
+			}
+		}
+
+		if options.resourceChecker != nil {
+			plan, _ := findPlan(catalog.Services(), serviceDetails.PlanID)
+			err := options.resourceChecker.CheckQuota(serviceDetails.OrganizationGUID, serviceDetails.PlanID, plan.ResourceQuotas)
+			if err != nil {
+				if _, ok := err.(ResourceQuotaExceededError); ok {
+					logger.Error(resourceQuotaExceededErrorKey, err)
+					respond(w, statusUnprocessableEntity, ErrorResponse{
+						Description: err.Error(),
+					})
+				} else {
+					logger.Error(unknownErrorKey, err)
+					respond(w, http.StatusInternalServerError, ErrorResponse{
+						Description: err.Error(),
+					})
+				}
+				return
+			}
+		}
+
 		if err := serviceBroker.Provision(instanceID, serviceDetails); err != nil {
 			switch err {
 			case ErrInstanceAlreadyExists:
-				logger.Error(instanceAlreadyExistsErrorKey, err)
+				logBrokerError(logger, options.errorLogLevels, instanceAlreadyExistsErrorKey, err)
 				respond(w, http.StatusConflict, EmptyResponse{})
 			case ErrInstanceLimitMet:
-				logger.Error(instanceLimitReachedErrorKey, err)
+				logBrokerError(logger, options.errorLogLevels, instanceLimitReachedErrorKey, err)
 				respond(w, http.StatusInternalServerError, ErrorResponse{
 					Description: err.Error(),
 				})
+			case ErrQuotaExceeded:
+				logBrokerError(logger, options.errorLogLevels, quotaExceededErrorKey, err, lager.Data{
+					"organization-guid": serviceDetails.OrganizationGUID,
+					"space-guid":        serviceDetails.SpaceGUID,
+				})
+				description := fmt.Sprintf("%s for organization %s and space %s", err, serviceDetails.OrganizationGUID, serviceDetails.SpaceGUID)
+				if message, found := planErrorMessage(catalog.Services(), serviceDetails.PlanID, ErrorCodeQuotaExceeded); found {
+					description = message
+				}
+				respond(w, statusUnprocessableEntity, ErrorResponse{
+					Description: description,
+				})
+			case ErrConcurrentOperation:
+				logBrokerError(logger, options.errorLogLevels, concurrentOperationErrorKey, err)
+				respond(w, statusUnprocessableEntity, ErrorResponse{
+					Error:       ErrorCodeConcurrencyError,
+					Description: err.Error(),
+				})
+			case ErrServiceUnavailable:
+				logBrokerError(logger, options.errorLogLevels, serviceUnavailableErrorKey, err)
+				respondServiceUnavailable(w, err)
 			default:
-				logger.Error(unknownErrorKey, err)
+				logBrokerError(logger, options.errorLogLevels, unknownErrorKey, err)
 				respond(w, http.StatusInternalServerError, ErrorResponse{
 					Description: err.Error(),
 				})
@@ -101,25 +491,77 @@ func provision(serviceBroker ServiceBroker, router httpRouter, logger lager.Logg
 			return
 		}
 
-		respond(w, http.StatusCreated, ProvisioningResponse{})
+		notifier.notify(WebhookEvent{
+			EventType:        WebhookEventProvisioned,
+			InstanceID:       instanceID,
+			ServiceID:        serviceDetails.ID,
+			PlanID:           serviceDetails.PlanID,
+			OrganizationGUID: serviceDetails.OrganizationGUID,
+			SpaceGUID:        serviceDetails.SpaceGUID,
+		})
+
+		if options.locationHeader {
+			w.Header().Set("Location", "/v2/service_instances/"+instanceID)
+		}
+
+		provisioningResponse := ProvisioningResponse{}
+		if options.parameterEcho && req.URL.Query().Get("return_parameters") == "true" {
+			provisioningResponse.Parameters = serviceDetails.Parameters
+		}
+
+		respond(w, http.StatusCreated, provisioningResponse)
 	}
 }
 
-func deprovision(serviceBroker ServiceBroker, router httpRouter, logger lager.Logger) http.HandlerFunc {
+func deprovision(serviceBroker ServiceBroker, router httpRouter, logger lager.Logger, options options, notifier *eventNotifier) http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
 		vars := router.Vars(req)
 		instanceID := vars["instance_id"]
 		logger := logger.Session(deprovisionLogKey, lager.Data{
 			instanceIDLogKey: instanceID,
 		})
+		catalog := newRequestCatalog(serviceBroker)
+		logger = withServiceAndPlanIDs(logger, catalog, options, req.URL.Query().Get("service_id"), req.URL.Query().Get("plan_id"))
+
+		if counter, ok := options.store.(PlanInstanceCounter); ok && options.instanceLimits {
+			if err := checkMinimumInstances(catalog, options.store, counter, instanceID); err != nil {
+				if err == errMinimumInstancesReserved {
+					logger.Error(minimumInstancesReservedErrorKey, err)
+					respond(w, http.StatusConflict, ErrorResponse{
+						Description: err.Error(),
+					})
+				} else {
+					logger.Error(unknownErrorKey, err)
+					respond(w, http.StatusInternalServerError, ErrorResponse{
+						Description: err.Error(),
+					})
+				}
+				return
+			}
+		}
 
 		if err := serviceBroker.Deprovision(instanceID); err != nil {
+			if goneErr, ok := err.(InstanceGoneError); ok {
+				logBrokerError(logger, options.errorLogLevels, instanceMissingErrorKey, err)
+				respondGone(w, goneErr.Description)
+				return
+			}
+
 			switch err {
 			case ErrInstanceDoesNotExist:
-				logger.Error(instanceMissingErrorKey, err)
+				logBrokerError(logger, options.errorLogLevels, instanceMissingErrorKey, err)
 				respond(w, http.StatusGone, EmptyResponse{})
+			case ErrConcurrentOperation:
+				logBrokerError(logger, options.errorLogLevels, concurrentOperationErrorKey, err)
+				respond(w, statusUnprocessableEntity, ErrorResponse{
+					Error:       ErrorCodeConcurrencyError,
+					Description: err.Error(),
+				})
+			case ErrServiceUnavailable:
+				logBrokerError(logger, options.errorLogLevels, serviceUnavailableErrorKey, err)
+				respondServiceUnavailable(w, err)
 			default:
-				logger.Error(unknownErrorKey, err)
+				logBrokerError(logger, options.errorLogLevels, unknownErrorKey, err)
 				respond(w, http.StatusInternalServerError, ErrorResponse{
 					Description: err.Error(),
 				})
@@ -127,11 +569,18 @@ func deprovision(serviceBroker ServiceBroker, router httpRouter, logger lager.Lo
 			return
 		}
 
+		notifier.notify(WebhookEvent{
+			EventType:  WebhookEventDeprovisioned,
+			InstanceID: instanceID,
+			ServiceID:  req.URL.Query().Get("service_id"),
+			PlanID:     req.URL.Query().Get("plan_id"),
+		})
+
 		respond(w, http.StatusOK, EmptyResponse{})
 	}
 }
 
-func bind(serviceBroker ServiceBroker, router httpRouter, logger lager.Logger) http.HandlerFunc {
+func bind(serviceBroker ServiceBroker, router httpRouter, logger lager.Logger, options options, notifier *eventNotifier) http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
 		vars := router.Vars(req)
 		instanceID := vars["instance_id"]
@@ -142,21 +591,76 @@ func bind(serviceBroker ServiceBroker, router httpRouter, logger lager.Logger) h
 			bindingIDLogKey:  bindingID,
 		})
 
+		var bindDetails BindDetails
+		if err := json.NewDecoder(req.Body).Decode(&bindDetails); err != nil && err != io.EOF {
+			if len(options.exclusiveBindResourceServiceIDs) > 0 {
+				logger.Error(invalidBindDetailsErrorKey, err)
+				respond(w, statusUnprocessableEntity, ErrorResponse{
+					Description: err.Error(),
+				})
+				return
+			}
+			bindDetails = BindDetails{}
+		}
+
+		catalog := newRequestCatalog(serviceBroker)
+		logger = withServiceAndPlanIDs(logger, catalog, options, bindDetails.ServiceID, bindDetails.PlanID)
+
+		if err := validateBindResource(bindDetails, options.exclusiveBindResourceServiceIDs); err != nil {
+			logger.Error(invalidBindDetailsErrorKey, err)
+			respond(w, http.StatusBadRequest, ErrorResponse{
+				Description: err.Error(),
+			})
+			return
+		}
+
+		if err := validateContext(bindDetails.Context, options.contextValidators); err != nil {
+			logger.Error(invalidContextErrorKey, err)
+			respond(w, http.StatusBadRequest, ErrorResponse{
+				Description: err.Error(),
+			})
+			return
+		}
+
+		if options.bindableCheck && !bindable(catalog.Services(), bindDetails.ServiceID, bindDetails.PlanID) {
+			logger.Error(notBindableErrorKey, errNotBindable)
+			respond(w, http.StatusBadRequest, ErrorResponse{
+				Description: errNotBindable.Error(),
+			})
+			return
+		}
+
+		if bindDetails.PlanID != "" {
+			plan, found := findPlan(catalog.Services(), bindDetails.PlanID)
+			if found && plan.Metadata.Deprecated {
+				logger.Info(deprecatedPlanErrorKey)
+			}
+		}
+
 		credentials, err := serviceBroker.Bind(instanceID, bindingID)
 		if err != nil {
 			switch err {
 			case ErrInstanceDoesNotExist:
-				logger.Error(instanceMissingErrorKey, err)
+				logBrokerError(logger, options.errorLogLevels, instanceMissingErrorKey, err)
 				respond(w, http.StatusNotFound, ErrorResponse{
 					Description: err.Error(),
 				})
 			case ErrBindingAlreadyExists:
-				logger.Error(bindingAlreadyExistsErrorKey, err)
+				logBrokerError(logger, options.errorLogLevels, bindingAlreadyExistsErrorKey, err)
 				respond(w, http.StatusConflict, ErrorResponse{
 					Description: err.Error(),
 				})
+			case ErrConcurrentOperation:
+				logBrokerError(logger, options.errorLogLevels, concurrentOperationErrorKey, err)
+				respond(w, statusUnprocessableEntity, ErrorResponse{
+					Error:       ErrorCodeConcurrencyError,
+					Description: err.Error(),
+				})
+			case ErrServiceUnavailable:
+				logBrokerError(logger, options.errorLogLevels, serviceUnavailableErrorKey, err)
+				respondServiceUnavailable(w, err)
 			default:
-				logger.Error(unknownErrorKey, err)
+				logBrokerError(logger, options.errorLogLevels, unknownErrorKey, err)
 				respond(w, http.StatusInternalServerError, ErrorResponse{
 					Description: err.Error(),
 				})
@@ -164,6 +668,30 @@ func bind(serviceBroker ServiceBroker, router httpRouter, logger lager.Logger) h
 			return
 		}
 
+		if credentialsFunc, ok := credentials.(CredentialsFunc); ok {
+			var err error
+			credentials, err = credentialsFunc()
+			if err != nil {
+				logger.Error(credentialsErrorKey, err)
+				respond(w, http.StatusInternalServerError, ErrorResponse{
+					Description: err.Error(),
+				})
+				return
+			}
+		}
+
+		if options.bindingExpiry != nil && bindDetails.BindingTTL != nil && *bindDetails.BindingTTL > 0 {
+			expire := expireBinding(serviceBroker, instanceID, bindingID, bindDetails, options.bindingExpiry, logger, notifier)
+			options.bindingExpiry.schedule(instanceID, bindingID, time.Duration(*bindDetails.BindingTTL)*time.Second, expire)
+		}
+
+		if options.credentialsKey != defaultCredentialsKey {
+			respond(w, http.StatusCreated, map[string]interface{}{
+				options.credentialsKey: credentials,
+			})
+			return
+		}
+
 		bindingResponse := BindingResponse{
 			Credentials: credentials,
 		}
@@ -172,7 +700,7 @@ func bind(serviceBroker ServiceBroker, router httpRouter, logger lager.Logger) h
 	}
 }
 
-func unbind(serviceBroker ServiceBroker, router httpRouter, logger lager.Logger) http.HandlerFunc {
+func unbind(serviceBroker ServiceBroker, router httpRouter, logger lager.Logger, options options) http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
 		vars := router.Vars(req)
 		instanceID := vars["instance_id"]
@@ -182,17 +710,28 @@ func unbind(serviceBroker ServiceBroker, router httpRouter, logger lager.Logger)
 			instanceIDLogKey: instanceID,
 			bindingIDLogKey:  bindingID,
 		})
+		catalog := newRequestCatalog(serviceBroker)
+		logger = withServiceAndPlanIDs(logger, catalog, options, req.URL.Query().Get("service_id"), req.URL.Query().Get("plan_id"))
 
 		if err := serviceBroker.Unbind(instanceID, bindingID); err != nil {
 			switch err {
 			case ErrInstanceDoesNotExist:
-				logger.Error(instanceMissingErrorKey, err)
+				logBrokerError(logger, options.errorLogLevels, instanceMissingErrorKey, err)
 				respond(w, http.StatusNotFound, EmptyResponse{})
 			case ErrBindingDoesNotExist:
-				logger.Error(bindingMissingErrorKey, err)
+				logBrokerError(logger, options.errorLogLevels, bindingMissingErrorKey, err)
 				respond(w, http.StatusGone, EmptyResponse{})
+			case ErrConcurrentOperation:
+				logBrokerError(logger, options.errorLogLevels, concurrentOperationErrorKey, err)
+				respond(w, statusUnprocessableEntity, ErrorResponse{
+					Error:       ErrorCodeConcurrencyError,
+					Description: err.Error(),
+				})
+			case ErrServiceUnavailable:
+				logBrokerError(logger, options.errorLogLevels, serviceUnavailableErrorKey, err)
+				respondServiceUnavailable(w, err)
 			default:
-				logger.Error(unknownErrorKey, err)
+				logBrokerError(logger, options.errorLogLevels, unknownErrorKey, err)
 				respond(w, http.StatusInternalServerError, ErrorResponse{
 					Description: err.Error(),
 				})
@@ -200,10 +739,70 @@ func unbind(serviceBroker ServiceBroker, router httpRouter, logger lager.Logger)
 			return
 		}
 
+		if options.bindingExpiry != nil {
+			options.bindingExpiry.cancel(instanceID, bindingID)
+		}
+
 		respond(w, http.StatusOK, EmptyResponse{})
 	}
 }
 
+func withServiceAndPlanIDs(logger lager.Logger, catalog *requestCatalog, options options, serviceID, planID string) lager.Logger {
+	data := lager.Data{}
+	if serviceID != "" {
+		data[serviceIDLogKey] = serviceID
+	}
+	if planID != "" {
+		data[planIDLogKey] = planID
+		if options.planNameLogging {
+			data[planNameLogKey] = planID
+			if plan, found := findPlan(catalog.Services(), planID); found && plan.Name != "" {
+				data[planNameLogKey] = plan.Name
+			}
+		}
+	}
+
+	if len(data) == 0 {
+		return logger
+	}
+	return logger.WithData(data)
+}
+
+func planErrorMessage(services []Service, planID, code string) (string, bool) {
+	plan, found := findPlan(services, planID)
+	if !found {
+		return "", false
+	}
+
+	message, found := plan.ErrorMessages[code]
+	return message, found && message != ""
+}
+
+func deprecatedPlanError(plan ServicePlan) error {
+	if plan.Metadata.DeprecationMessage == "" {
+		return errors.New("this plan is deprecated")
+	}
+	return errors.New(plan.Metadata.DeprecationMessage)
+}
+
+func respondServiceUnavailable(w http.ResponseWriter, err error) {
+	w.Header().Set("Retry-After", serviceUnavailableRetryAfterSeconds)
+	respond(w, http.StatusServiceUnavailable, ErrorResponse{
+		Description: err.Error(),
+	})
+}
+
+func respondGone(w http.ResponseWriter, description string) {
+	if description == "" {
+		respond(w, http.StatusGone, EmptyResponse{})
+		return
+	}
+
+	respond(w, http.StatusGone, ErrorResponse{
+		Description: description,
+	})
+}
+
 func respond(w http.ResponseWriter, status int, response interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -211,3 +810,17 @@ func respond(w http.ResponseWriter, status int, response interface{}) {
 	encoder := json.NewEncoder(w)
 	encoder.Encode(response)
 }
+
+func respondIndented(w http.ResponseWriter, status int, response interface{}) {
+	body, err := json.MarshalIndent(response, "", "  ")
+	if err != nil {
+		respond(w, http.StatusInternalServerError, ErrorResponse{
+			Description: err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(append(body, '\n'))
+}
@@ -2,6 +2,8 @@ package brokerapi_test
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -64,6 +66,157 @@ var _ = Describe("Service Broker API", func() {
 		brokerAPI = brokerapi.New(fakeServiceBroker, brokerLogger, credentials)
 	})
 
+	Describe("catalog validation", func() {
+		It("accepts a catalog with valid IDs", func() {
+			handler, err := brokerapi.NewHandler(fakeServiceBroker, brokerLogger, credentials, brokerapi.WithCatalogValidation())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(handler).NotTo(BeNil())
+		})
+
+		Context("when the catalog has invalid IDs", func() {
+			BeforeEach(func() {
+				fakeServiceBroker.Catalog = []brokerapi.Service{
+					{ID: "not-a-uuid", Name: "p-cassandra"},
+				}
+			})
+
+			It("returns an error from NewHandler", func() {
+				handler, err := brokerapi.NewHandler(fakeServiceBroker, brokerLogger, credentials, brokerapi.WithCatalogValidation())
+				Expect(handler).To(BeNil())
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(`service "p-cassandra" has invalid id "not-a-uuid"`))
+			})
+
+			It("logs the invalid IDs from New without exiting", func() {
+				Expect(func() {
+					brokerapi.New(fakeServiceBroker, brokerLogger, credentials, brokerapi.WithCatalogValidation())
+				}).NotTo(Panic())
+
+				Expect(lastLogLine().Message).To(ContainSubstring("broker-api.invalid-catalog"))
+				Expect(lastLogLine().LogLevel).To(Equal(lager.ERROR))
+				Expect(lastLogLine().Data["error"]).To(ContainSubstring(`service "p-cassandra" has invalid id "not-a-uuid"`))
+			})
+
+			It("does not validate unless asked to", func() {
+				_, err := brokerapi.NewHandler(fakeServiceBroker, brokerLogger, credentials)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("built-in store", func() {
+		var serviceDetails brokerapi.ServiceDetails
+
+		makeRequest := func(method, path string) *testflight.Response {
+			response := &testflight.Response{}
+			testflight.WithServer(brokerAPI, func(r *testflight.Requester) {
+				request, _ := http.NewRequest(method, path, strings.NewReader(""))
+				request.SetBasicAuth(credentials.Username, credentials.Password)
+				response = r.Do(request)
+			})
+			return response
+		}
+
+		BeforeEach(func() {
+			serviceDetails = brokerapi.ServiceDetails{
+				ID:     "service-id",
+				PlanID: "plan-id",
+			}
+			brokerAPI = brokerapi.New(fakeServiceBroker, brokerLogger, credentials, brokerapi.WithStore(brokerapi.NewMemoryStore()))
+		})
+
+		It("rejects duplicate provisions without calling the broker", func() {
+			instanceID := uniqueInstanceID()
+			response := makeInstanceProvisioningRequest(instanceID, serviceDetails)
+			Expect(response.StatusCode).To(Equal(201))
+
+			fakeServiceBroker.BrokerCalled = false
+			response = makeInstanceProvisioningRequest(instanceID, serviceDetails)
+			Expect(response.StatusCode).To(Equal(409))
+			Expect(fakeServiceBroker.BrokerCalled).To(BeFalse())
+		})
+
+		It("rejects unbinds from missing instances without calling the broker", func() {
+			path := fmt.Sprintf("/v2/service_instances/%s/service_bindings/%s", uniqueInstanceID(), uniqueBindingID())
+			response := makeRequest("DELETE", path)
+			Expect(response.StatusCode).To(Equal(404))
+			Expect(fakeServiceBroker.BrokerCalled).To(BeFalse())
+		})
+
+		It("rejects deprovisions of missing instances without calling the broker", func() {
+			response := makeRequest("DELETE", "/v2/service_instances/"+uniqueInstanceID())
+			Expect(response.StatusCode).To(Equal(410))
+			Expect(fakeServiceBroker.BrokerCalled).To(BeFalse())
+		})
+
+		It("tracks bindings of provisioned instances", func() {
+			instanceID := uniqueInstanceID()
+			bindingPath := fmt.Sprintf("/v2/service_instances/%s/service_bindings/%s", instanceID, uniqueBindingID())
+
+			makeInstanceProvisioningRequest(instanceID, serviceDetails)
+			Expect(makeRequest("PUT", bindingPath).StatusCode).To(Equal(201))
+			Expect(makeRequest("PUT", bindingPath).StatusCode).To(Equal(409))
+			Expect(makeRequest("DELETE", bindingPath).StatusCode).To(Equal(200))
+
+			fakeServiceBroker.BrokerCalled = false
+			Expect(makeRequest("DELETE", bindingPath).StatusCode).To(Equal(410))
+			Expect(fakeServiceBroker.BrokerCalled).To(BeFalse())
+		})
+
+		It("allows an instance to be provisioned again when the broker fails", func() {
+			instanceID := uniqueInstanceID()
+			fakeServiceBroker.ProvisionError = errors.New("broker failed")
+			Expect(makeInstanceProvisioningRequest(instanceID, serviceDetails).StatusCode).To(Equal(500))
+
+			fakeServiceBroker.ProvisionError = nil
+			Expect(makeInstanceProvisioningRequest(instanceID, serviceDetails).StatusCode).To(Equal(201))
+		})
+	})
+
+	Describe("OPTIONS requests", func() {
+		makeOptionsRequest := func(path string) *httptest.ResponseRecorder {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("OPTIONS", path, nil)
+			request.SetBasicAuth(credentials.Username, credentials.Password)
+			brokerAPI.ServeHTTP(recorder, request)
+			return recorder
+		}
+
+		It("are not answered by default", func() {
+			Expect(makeOptionsRequest("/v2/catalog").Code).To(Equal(404))
+		})
+
+		Context("when OPTIONS responses are enabled", func() {
+			BeforeEach(func() {
+				brokerAPI = brokerapi.New(fakeServiceBroker, brokerLogger, credentials, brokerapi.WithOptionsResponses())
+			})
+
+			It("lists the methods of the catalog route", func() {
+				response := makeOptionsRequest("/v2/catalog")
+				Expect(response.Code).To(Equal(204))
+				Expect(response.Header().Get("Allow")).To(Equal("GET, OPTIONS"))
+			})
+
+			It("lists the methods of the instance route", func() {
+				response := makeOptionsRequest("/v2/service_instances/" + uniqueInstanceID())
+				Expect(response.Code).To(Equal(204))
+				Expect(response.Header().Get("Allow")).To(Equal("PUT, DELETE, OPTIONS"))
+				Expect(fakeServiceBroker.BrokerCalled).To(BeFalse())
+			})
+
+			It("still returns a 404 for unknown routes", func() {
+				Expect(makeOptionsRequest("/v2/unknown").Code).To(Equal(404))
+			})
+
+			It("still requires broker credentials", func() {
+				recorder := httptest.NewRecorder()
+				request, _ := http.NewRequest("OPTIONS", "/v2/catalog", nil)
+				brokerAPI.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(401))
+			})
+		})
+	})
+
 	Describe("respose headers", func() {
 		makeRequest := func() *httptest.ResponseRecorder {
 			recorder := httptest.NewRecorder()
@@ -79,6 +232,391 @@ var _ = Describe("Service Broker API", func() {
 			header := response.Header().Get("Content-Type")
 			Ω(header).Should(Equal("application/json"))
 		})
+
+		It("has an X-Broker-API-Version header with the default version", func() {
+			response := makeRequest()
+
+			header := response.Header().Get("X-Broker-API-Version")
+			Ω(header).Should(Equal(brokerapi.DefaultAPIVersion))
+		})
+
+		It("has an X-Broker-API-Version header with the configured version", func() {
+			brokerAPI = brokerapi.New(fakeServiceBroker, brokerLogger, credentials, brokerapi.WithAPIVersion("2.5"))
+			response := makeRequest()
+
+			header := response.Header().Get("X-Broker-API-Version")
+			Ω(header).Should(Equal("2.5"))
+		})
+
+		It("has an X-Broker-API-Version header on unauthorized responses", func() {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("GET", "/v2/catalog", nil)
+			brokerAPI.ServeHTTP(recorder, request)
+
+			Ω(recorder.Code).Should(Equal(401))
+			Ω(recorder.Header().Get("X-Broker-API-Version")).Should(Equal(brokerapi.DefaultAPIVersion))
+		})
+	})
+
+	Describe("access log", func() {
+		makeProvisioningRequestWithBody := func(body string) *httptest.ResponseRecorder {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("PUT", "/v2/service_instances/"+uniqueInstanceID(), strings.NewReader(body))
+			request.SetBasicAuth(credentials.Username, credentials.Password)
+			brokerAPI.ServeHTTP(recorder, request)
+			return recorder
+		}
+
+		makeCatalogRequest := func() *httptest.ResponseRecorder {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("GET", "/v2/catalog", nil)
+			request.SetBasicAuth(credentials.Username, credentials.Password)
+			brokerAPI.ServeHTTP(recorder, request)
+			return recorder
+		}
+
+		requestLogs := func() []lager.LogFormat {
+			logs := []lager.LogFormat{}
+			for _, log := range brokerLogger.Logs() {
+				if log.Message == "broker-api.request" {
+					logs = append(logs, log)
+				}
+			}
+			return logs
+		}
+
+		It("is off by default", func() {
+			makeProvisioningRequestWithBody(`{"plan_id":"plan-id"}`)
+			Expect(requestLogs()).To(BeEmpty())
+		})
+
+		Context("when body hash logging is enabled", func() {
+			BeforeEach(func() {
+				brokerAPI = brokerapi.New(fakeServiceBroker, brokerLogger, credentials, brokerapi.WithBodyHashLogging())
+			})
+
+			It("logs the SHA-256 of the raw request body", func() {
+				body := `{"plan_id":"plan-id"}`
+				makeProvisioningRequestWithBody(body)
+
+				logs := requestLogs()
+				Expect(logs).To(HaveLen(1))
+				Expect(logs[0].Data["method"]).To(Equal("PUT"))
+				Expect(logs[0].Data["body-sha256"]).To(MatchRegexp("^[0-9a-f]{64}$"))
+
+				sum := sha256.Sum256([]byte(body))
+				Expect(logs[0].Data["body-sha256"]).To(Equal(hex.EncodeToString(sum[:])))
+			})
+
+			It("logs a different hash when the body changes", func() {
+				makeProvisioningRequestWithBody(`{"plan_id":"plan-id"}`)
+				makeProvisioningRequestWithBody(`{"plan_id":"other-plan-id"}`)
+
+				logs := requestLogs()
+				Expect(logs).To(HaveLen(2))
+				Expect(logs[0].Data["body-sha256"]).NotTo(Equal(logs[1].Data["body-sha256"]))
+			})
+
+			It("still passes the body to the handler", func() {
+				response := makeProvisioningRequestWithBody(`{"plan_id":"plan-id"}`)
+				Expect(response.Code).To(Equal(201))
+				Expect(fakeServiceBroker.ServiceDetails.PlanID).To(Equal("plan-id"))
+			})
+
+			It("rejects bodies larger than 1MB without reading them fully", func() {
+				body := `{"plan_id":"plan-id","parameters":{"padding":"` + strings.Repeat("x", 1<<20) + `"}}`
+				response := makeProvisioningRequestWithBody(body)
+				Expect(response.Code).To(Equal(413))
+				Expect(fakeServiceBroker.BrokerCalled).To(BeFalse())
+			})
+		})
+
+		Context("when query logging is enabled", func() {
+			BeforeEach(func() {
+				brokerAPI = brokerapi.New(fakeServiceBroker, brokerLogger, credentials, brokerapi.WithQueryLogging("token"))
+			})
+
+			makeDeprovisionRequest := func(query string) {
+				recorder := httptest.NewRecorder()
+				request, _ := http.NewRequest("DELETE", "/v2/service_instances/"+uniqueInstanceID()+"?"+query, strings.NewReader(""))
+				request.SetBasicAuth(credentials.Username, credentials.Password)
+				brokerAPI.ServeHTTP(recorder, request)
+			}
+
+			It("logs the query string", func() {
+				makeDeprovisionRequest("service_id=service-id&plan_id=plan-id&accepts_incomplete=true")
+
+				logs := requestLogs()
+				Expect(logs).To(HaveLen(1))
+				Expect(logs[0].Data["query"]).To(Equal("accepts_incomplete=true&plan_id=plan-id&service_id=service-id"))
+			})
+
+			It("redacts the configured parameters", func() {
+				makeDeprovisionRequest("service_id=service-id&token=secret")
+
+				logs := requestLogs()
+				Expect(logs[0].Data["query"]).To(Equal("service_id=service-id&token=REDACTED"))
+			})
+
+			It("omits the query when there is none", func() {
+				makeCatalogRequest()
+				Expect(requestLogs()[0].Data).NotTo(HaveKey("query"))
+			})
+		})
+
+		Context("when access log levels are configured", func() {
+			BeforeEach(func() {
+				brokerAPI = brokerapi.New(fakeServiceBroker, brokerLogger, credentials, brokerapi.WithAccessLogLevels(brokerapi.DefaultAccessLogLevels))
+			})
+
+			It("logs catalog requests at debug level", func() {
+				makeCatalogRequest()
+
+				logs := requestLogs()
+				Expect(logs).To(HaveLen(1))
+				Expect(logs[0].Data["method"]).To(Equal("GET"))
+				Expect(logs[0].Data["path"]).To(Equal("/v2/catalog"))
+				Expect(logs[0].LogLevel).To(Equal(lager.DEBUG))
+			})
+
+			It("logs provision requests at info level", func() {
+				makeProvisioningRequestWithBody(`{"plan_id":"plan-id"}`)
+
+				logs := requestLogs()
+				Expect(logs).To(HaveLen(1))
+				Expect(logs[0].Data["method"]).To(Equal("PUT"))
+				Expect(logs[0].LogLevel).To(Equal(lager.INFO))
+			})
+
+			It("logs methods missing from the map at info level", func() {
+				brokerAPI = brokerapi.New(fakeServiceBroker, brokerLogger, credentials, brokerapi.WithAccessLogLevels(map[string]lager.LogLevel{}))
+				makeCatalogRequest()
+
+				logs := requestLogs()
+				Expect(logs).To(HaveLen(1))
+				Expect(logs[0].LogLevel).To(Equal(lager.INFO))
+			})
+
+			It("does not log body hashes unless enabled", func() {
+				makeProvisioningRequestWithBody(`{"plan_id":"plan-id"}`)
+				Expect(requestLogs()[0].Data).NotTo(HaveKey("body-sha256"))
+			})
+
+			It("logs at error level when configured", func() {
+				brokerAPI = brokerapi.New(fakeServiceBroker, brokerLogger, credentials, brokerapi.WithAccessLogLevels(map[string]lager.LogLevel{
+					"GET": lager.ERROR,
+				}))
+				makeCatalogRequest()
+
+				logs := requestLogs()
+				Expect(logs).To(HaveLen(1))
+				Expect(logs[0].LogLevel).To(Equal(lager.ERROR))
+			})
+
+			It("rejects the fatal level", func() {
+				_, err := brokerapi.NewHandler(fakeServiceBroker, brokerLogger, credentials, brokerapi.WithAccessLogLevels(map[string]lager.LogLevel{
+					"GET": lager.FATAL,
+				}))
+				Expect(err).To(MatchError("unsupported access log level 3 for GET requests"))
+			})
+
+			It("logs fatal-level requests at info level from New without exiting", func() {
+				brokerAPI = brokerapi.New(fakeServiceBroker, brokerLogger, credentials, brokerapi.WithAccessLogLevels(map[string]lager.LogLevel{
+					"GET": lager.FATAL,
+				}))
+				Expect(lastLogLine().Message).To(ContainSubstring("invalid-configuration"))
+
+				Expect(func() { makeCatalogRequest() }).NotTo(Panic())
+				Expect(requestLogs()[0].LogLevel).To(Equal(lager.INFO))
+			})
+		})
+	})
+
+	Describe("common log format", func() {
+		var commonLog *bytes.Buffer
+
+		BeforeEach(func() {
+			commonLog = &bytes.Buffer{}
+			brokerAPI = brokerapi.New(fakeServiceBroker, brokerLogger, credentials, brokerapi.WithCommonLogFormat(commonLog))
+		})
+
+		It("writes a line for a catalog request", func() {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("GET", "/v2/catalog", nil)
+			request.RemoteAddr = "10.0.0.1:54321"
+			request.SetBasicAuth(credentials.Username, credentials.Password)
+			brokerAPI.ServeHTTP(recorder, request)
+
+			Expect(commonLog.String()).To(MatchRegexp(
+				`^10\.0\.0\.1 - username \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] "GET /v2/catalog HTTP/1\.1" 200 %d\n$`,
+				recorder.Body.Len(),
+			))
+		})
+
+		It("logs unauthorized requests", func() {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("GET", "/v2/catalog?page=1", nil)
+			brokerAPI.ServeHTTP(recorder, request)
+
+			Expect(commonLog.String()).To(ContainSubstring(`- - [`))
+			Expect(commonLog.String()).To(ContainSubstring(`"GET /v2/catalog?page=1 HTTP/1.1" 401 `))
+		})
+
+		It("does not replace the structured access log", func() {
+			brokerAPI = brokerapi.New(fakeServiceBroker, brokerLogger, credentials, brokerapi.WithCommonLogFormat(commonLog), brokerapi.WithBodyHashLogging())
+
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("GET", "/v2/catalog", strings.NewReader(""))
+			request.SetBasicAuth(credentials.Username, credentials.Password)
+			brokerAPI.ServeHTTP(recorder, request)
+
+			Expect(commonLog.String()).NotTo(BeEmpty())
+			Expect(lastLogLine().Message).To(Equal("broker-api.request"))
+		})
+	})
+
+	Describe("originating identity", func() {
+		const identity = "cloudfoundry eyJ1c2VyX2lkIjoiNjgzZWE3NDgtMzA5Mi00ZmY0LWI2NTYtMzljYWNjNGQ1MzYwIn0="
+
+		makeProvisioningRequest := func(identity string) *httptest.ResponseRecorder {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("PUT", "/v2/service_instances/"+uniqueInstanceID(), strings.NewReader(`{"plan_id":"plan-id"}`))
+			request.SetBasicAuth(credentials.Username, credentials.Password)
+			if identity != "" {
+				request.Header.Set("X-Broker-API-Originating-Identity", identity)
+			}
+			brokerAPI.ServeHTTP(recorder, request)
+			return recorder
+		}
+
+		Context("when the header is optional", func() {
+			It("accepts requests with the header", func() {
+				Expect(makeProvisioningRequest(identity).Code).To(Equal(201))
+			})
+
+			It("accepts requests without the header", func() {
+				Expect(makeProvisioningRequest("").Code).To(Equal(201))
+			})
+		})
+
+		Context("when the header is required", func() {
+			BeforeEach(func() {
+				brokerAPI = brokerapi.New(fakeServiceBroker, brokerLogger, credentials, brokerapi.WithRequiredOriginatingIdentity())
+			})
+
+			It("accepts requests with the header", func() {
+				Expect(makeProvisioningRequest(identity).Code).To(Equal(201))
+			})
+
+			It("rejects requests without the header", func() {
+				response := makeProvisioningRequest("")
+				Expect(response.Code).To(Equal(400))
+				Expect(response.Body.String()).To(MatchJSON(`{"description":"missing X-Broker-API-Originating-Identity header"}`))
+				Expect(fakeServiceBroker.BrokerCalled).To(BeFalse())
+				Expect(lastLogLine().Message).To(ContainSubstring("broker-api.originating-identity-missing"))
+			})
+
+			It("does not require the header for the catalog", func() {
+				recorder := httptest.NewRecorder()
+				request, _ := http.NewRequest("GET", "/v2/catalog", nil)
+				request.SetBasicAuth(credentials.Username, credentials.Password)
+				brokerAPI.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(200))
+			})
+		})
+	})
+
+	Describe("CORS", func() {
+		makeCatalogRequest := func(method, origin string, authenticated bool) *httptest.ResponseRecorder {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest(method, "/v2/catalog", nil)
+			request.Header.Set("Origin", origin)
+			if method == "OPTIONS" {
+				request.Header.Set("Access-Control-Request-Method", "GET")
+			}
+			if authenticated {
+				request.SetBasicAuth(credentials.Username, credentials.Password)
+			}
+			brokerAPI.ServeHTTP(recorder, request)
+			return recorder
+		}
+
+		It("is off by default", func() {
+			response := makeCatalogRequest("GET", "https://marketplace.example.com", true)
+			Expect(response.Code).To(Equal(200))
+			Expect(response.Header().Get("Access-Control-Allow-Origin")).To(BeEmpty())
+
+			response = makeCatalogRequest("OPTIONS", "https://marketplace.example.com", false)
+			Expect(response.Code).To(Equal(401))
+		})
+
+		Context("when configured", func() {
+			BeforeEach(func() {
+				brokerAPI = brokerapi.New(fakeServiceBroker, brokerLogger, credentials, brokerapi.WithCORS(brokerapi.CORSConfig{
+					AllowedOrigins: []string{"https://marketplace.example.com"},
+				}))
+			})
+
+			It("answers preflight requests from allowed origins without authentication", func() {
+				response := makeCatalogRequest("OPTIONS", "https://marketplace.example.com", false)
+				Expect(response.Code).To(Equal(204))
+				Expect(response.Header().Get("Access-Control-Allow-Origin")).To(Equal("https://marketplace.example.com"))
+				Expect(response.Header().Get("Access-Control-Allow-Methods")).To(Equal("GET"))
+				Expect(response.Header().Get("Access-Control-Allow-Headers")).To(ContainSubstring("Authorization"))
+				Expect(fakeServiceBroker.BrokerCalled).To(BeFalse())
+			})
+
+			It("allows cross-origin catalog requests from allowed origins", func() {
+				response := makeCatalogRequest("GET", "https://marketplace.example.com", true)
+				Expect(response.Code).To(Equal(200))
+				Expect(response.Header().Get("Access-Control-Allow-Origin")).To(Equal("https://marketplace.example.com"))
+				Expect(response.Body.String()).To(MatchJSON(fixture("catalog.json")))
+			})
+
+			It("still requires authentication for the catalog", func() {
+				response := makeCatalogRequest("GET", "https://marketplace.example.com", false)
+				Expect(response.Code).To(Equal(401))
+			})
+
+			It("does not allow other origins", func() {
+				response := makeCatalogRequest("GET", "https://evil.example.com", true)
+				Expect(response.Header().Get("Access-Control-Allow-Origin")).To(BeEmpty())
+
+				response = makeCatalogRequest("OPTIONS", "https://evil.example.com", false)
+				Expect(response.Code).To(Equal(401))
+			})
+		})
+
+		Context("when configured with a wildcard origin", func() {
+			BeforeEach(func() {
+				brokerAPI = brokerapi.New(fakeServiceBroker, brokerLogger, credentials, brokerapi.WithCORS(brokerapi.CORSConfig{
+					AllowedOrigins: []string{"*"},
+				}))
+			})
+
+			It("allows any origin without credentials", func() {
+				response := makeCatalogRequest("GET", "https://evil.example.com", true)
+				Expect(response.Header().Get("Access-Control-Allow-Origin")).To(Equal("*"))
+				Expect(response.Header().Get("Access-Control-Allow-Credentials")).To(BeEmpty())
+			})
+
+			It("never allows credentials on preflight requests", func() {
+				response := makeCatalogRequest("OPTIONS", "https://evil.example.com", false)
+				Expect(response.Code).To(Equal(204))
+				Expect(response.Header().Get("Access-Control-Allow-Origin")).To(Equal("*"))
+				Expect(response.Header().Get("Access-Control-Allow-Credentials")).To(BeEmpty())
+			})
+
+			It("allows credentials for origins that are also listed explicitly", func() {
+				brokerAPI = brokerapi.New(fakeServiceBroker, brokerLogger, credentials, brokerapi.WithCORS(brokerapi.CORSConfig{
+					AllowedOrigins: []string{"*", "https://marketplace.example.com"},
+				}))
+
+				response := makeCatalogRequest("GET", "https://marketplace.example.com", true)
+				Expect(response.Header().Get("Access-Control-Allow-Origin")).To(Equal("https://marketplace.example.com"))
+				Expect(response.Header().Get("Access-Control-Allow-Credentials")).To(Equal("true"))
+			})
+		})
 	})
 
 	Describe("authentication", func() {
@@ -164,6 +702,197 @@ var _ = Describe("Service Broker API", func() {
 			response := makeCatalogRequest()
 			Expect(response.Body).To(MatchJSON(fixture("catalog.json")))
 		})
+
+		It("returns compact json by default", func() {
+			response := makeCatalogRequest()
+			Expect(response.Body).NotTo(ContainSubstring("\n  "))
+		})
+
+		Context("when the catalog is configured to be indented", func() {
+			var compactBody string
+
+			BeforeEach(func() {
+				compactBody = makeCatalogRequest().Body
+				brokerAPI = brokerapi.New(fakeServiceBroker, brokerLogger, credentials, brokerapi.WithIndentedCatalog())
+			})
+
+			It("returns indented json", func() {
+				response := makeCatalogRequest()
+				Expect(response.StatusCode).To(Equal(200))
+				Expect(response.RawResponse.Header.Get("Content-Type")).To(Equal("application/json"))
+				Expect(response.Body).To(ContainSubstring("\n  \"services\": ["))
+			})
+
+			It("returns the same catalog as the compact json", func() {
+				response := makeCatalogRequest()
+				Expect(response.Body).NotTo(Equal(compactBody))
+				Expect(response.Body).To(MatchJSON(compactBody))
+			})
+		})
+
+		Context("when a plan health check is configured", func() {
+			BeforeEach(func() {
+				fakeServiceBroker.Catalog = []brokerapi.Service{
+					{
+						ID:   "service-1",
+						Name: "p-cassandra",
+						Plans: []brokerapi.ServicePlan{
+							{ID: "plan-us-east", Name: "us-east"},
+							{ID: "plan-eu-west", Name: "eu-west"},
+						},
+					},
+					{
+						ID:   "service-2",
+						Name: "p-redis",
+						Plans: []brokerapi.ServicePlan{
+							{ID: "plan-eu-west-redis", Name: "eu-west"},
+						},
+					},
+				}
+
+				planHealthy := func(planID string) bool {
+					return planID == "plan-us-east"
+				}
+				brokerAPI = brokerapi.New(fakeServiceBroker, brokerLogger, credentials, brokerapi.WithPlanHealthCheck(planHealthy))
+			})
+
+			It("omits plans that are not healthy", func() {
+				response := makeCatalogRequest()
+				Expect(response.StatusCode).To(Equal(200))
+
+				var catalog brokerapi.CatalogResponse
+				err := json.Unmarshal([]byte(response.Body), &catalog)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(catalog.Services).To(HaveLen(1))
+				Expect(catalog.Services[0].ID).To(Equal("service-1"))
+				Expect(catalog.Services[0].Plans).To(Equal([]brokerapi.ServicePlan{
+					{ID: "plan-us-east", Name: "us-east"},
+				}))
+			})
+		})
+	})
+
+	Describe("service plans endpoint", func() {
+		makeServicePlansRequest := func(query string) *testflight.Response {
+			response := &testflight.Response{}
+			testflight.WithServer(brokerAPI, func(r *testflight.Requester) {
+				request, _ := http.NewRequest("GET", "/v2/service_plans"+query, nil)
+				request.SetBasicAuth("username", "password")
+
+				response = r.Do(request)
+			})
+			return response
+		}
+
+		BeforeEach(func() {
+			fakeServiceBroker.Catalog = []brokerapi.Service{
+				{
+					ID: "service-1",
+					Plans: []brokerapi.ServicePlan{
+						{ID: "plan-1", Name: "small"},
+						{ID: "plan-2", Name: "large"},
+					},
+				},
+				{
+					ID: "service-2",
+					Plans: []brokerapi.ServicePlan{
+						{ID: "plan-3", Name: "default"},
+					},
+				},
+				{
+					ID:    "service-3",
+					Plans: []brokerapi.ServicePlan{},
+				},
+			}
+		})
+
+		It("is not served unless extended endpoints are enabled", func() {
+			response := makeServicePlansRequest("")
+			Expect(response.StatusCode).To(Equal(404))
+		})
+
+		Context("when extended endpoints are enabled", func() {
+			BeforeEach(func() {
+				brokerAPI = brokerapi.New(fakeServiceBroker, brokerLogger, credentials, brokerapi.WithExtendedEndpoints())
+			})
+
+			It("lists the plans of every service", func() {
+				response := makeServicePlansRequest("")
+				Expect(response.StatusCode).To(Equal(200))
+				Expect(response.Body).To(MatchJSON(`{
+					"plans": [
+						{"id":"plan-1","name":"small","description":"","metadata":{"bullets":null,"displayName":""}},
+						{"id":"plan-2","name":"large","description":"","metadata":{"bullets":null,"displayName":""}},
+						{"id":"plan-3","name":"default","description":"","metadata":{"bullets":null,"displayName":""}}
+					],
+					"total_results": 3,
+					"total_pages": 1
+				}`))
+			})
+
+			It("filters plans by service ID", func() {
+				response := makeServicePlansRequest("?service_id=service-2")
+				Expect(response.StatusCode).To(Equal(200))
+				Expect(response.Body).To(MatchJSON(`{
+					"plans": [
+						{"id":"plan-3","name":"default","description":"","metadata":{"bullets":null,"displayName":""}}
+					],
+					"total_results": 1,
+					"total_pages": 1
+				}`))
+			})
+
+			It("returns an empty list for a service without plans", func() {
+				response := makeServicePlansRequest("?service_id=service-3")
+				Expect(response.StatusCode).To(Equal(200))
+				Expect(response.Body).To(MatchJSON(`{"plans":[],"total_results":0,"total_pages":0}`))
+			})
+
+			It("pages through the plans with offset and limit", func() {
+				response := makeServicePlansRequest("?limit=2")
+				Expect(response.StatusCode).To(Equal(200))
+				Expect(response.Body).To(MatchJSON(`{
+					"plans": [
+						{"id":"plan-1","name":"small","description":"","metadata":{"bullets":null,"displayName":""}},
+						{"id":"plan-2","name":"large","description":"","metadata":{"bullets":null,"displayName":""}}
+					],
+					"total_results": 3,
+					"total_pages": 2
+				}`))
+
+				response = makeServicePlansRequest("?offset=2&limit=2")
+				Expect(response.StatusCode).To(Equal(200))
+				Expect(response.Body).To(MatchJSON(`{
+					"plans": [
+						{"id":"plan-3","name":"default","description":"","metadata":{"bullets":null,"displayName":""}}
+					],
+					"total_results": 3,
+					"total_pages": 2
+				}`))
+			})
+
+			It("returns an empty page for an offset past the last plan", func() {
+				response := makeServicePlansRequest("?offset=5&limit=2")
+				Expect(response.StatusCode).To(Equal(200))
+				Expect(response.Body).To(MatchJSON(`{"plans":[],"total_results":3,"total_pages":2}`))
+			})
+
+			It("rejects an invalid offset or limit", func() {
+				response := makeServicePlansRequest("?offset=-1")
+				Expect(response.StatusCode).To(Equal(400))
+				Expect(response.Body).To(MatchJSON(`{"description":"offset must be a non-negative integer, got \"-1\""}`))
+
+				response = makeServicePlansRequest("?limit=0")
+				Expect(response.StatusCode).To(Equal(400))
+				Expect(response.Body).To(MatchJSON(`{"description":"limit must be a positive integer, got \"0\""}`))
+			})
+
+			It("returns a 404 for an unknown service ID", func() {
+				response := makeServicePlansRequest("?service_id=does-not-exist")
+				Expect(response.StatusCode).To(Equal(404))
+				Expect(response.Body).To(MatchJSON(`{"description":"service does not exist"}`))
+			})
+		})
 	})
 
 	Describe("instance lifecycle endpoint", func() {
@@ -204,39 +933,138 @@ var _ = Describe("Service Broker API", func() {
 				Expect(fakeServiceBroker.ProvisionedInstanceIDs).To(ContainElement(instanceID))
 			})
 
-			Context("when the instance does not exist", func() {
-				It("returns a 201", func() {
-					response := makeInstanceProvisioningRequest(instanceID, serviceDetails)
-					Expect(response.StatusCode).To(Equal(201))
+			Context("when the instance does not exist", func() {
+				It("returns a 201", func() {
+					response := makeInstanceProvisioningRequest(instanceID, serviceDetails)
+					Expect(response.StatusCode).To(Equal(201))
+				})
+
+				It("returns json with a dashboard_url field", func() {
+					response := makeInstanceProvisioningRequest(instanceID, serviceDetails)
+					Expect(response.Body).To(MatchJSON(fixture("provisioning.json")))
+				})
+
+				It("does not return a Location header", func() {
+					response := makeInstanceProvisioningRequest(instanceID, serviceDetails)
+					Expect(response.RawResponse.Header.Get("Location")).To(BeEmpty())
+				})
+
+				Context("when the Location header is enabled", func() {
+					BeforeEach(func() {
+						brokerAPI = brokerapi.New(fakeServiceBroker, brokerLogger, credentials, brokerapi.WithLocationHeader())
+					})
+
+					It("returns the instance URL in the Location header", func() {
+						response := makeInstanceProvisioningRequest(instanceID, serviceDetails)
+						Expect(response.StatusCode).To(Equal(201))
+						Expect(response.RawResponse.Header.Get("Location")).To(Equal("/v2/service_instances/" + instanceID))
+					})
+				})
+
+				Context("when the instance limit has been reached", func() {
+					BeforeEach(func() {
+						for i := 0; i < fakeServiceBroker.InstanceLimit; i++ {
+							makeInstanceProvisioningRequest(uniqueInstanceID(), serviceDetails)
+						}
+					})
+
+					It("returns a 500", func() {
+						response := makeInstanceProvisioningRequest(instanceID, serviceDetails)
+						Expect(response.StatusCode).To(Equal(500))
+					})
+
+					It("returns json with a description field and a useful error message", func() {
+						response := makeInstanceProvisioningRequest(instanceID, serviceDetails)
+						Expect(response.Body).To(MatchJSON(fixture("instance_limit_error.json")))
+					})
+
+					It("logs an appropriate error", func() {
+						makeInstanceProvisioningRequest(instanceID, serviceDetails)
+
+						Expect(lastLogLine().Message).To(ContainSubstring("provision.instance-limit-reached"))
+						Expect(lastLogLine().Data["error"]).To(ContainSubstring("instance limit for this service has been reached"))
+					})
+				})
+
+				Context("when the org or space quota has been exceeded", func() {
+					BeforeEach(func() {
+						fakeServiceBroker.ProvisionError = brokerapi.ErrQuotaExceeded
+					})
+
+					It("returns a 422 naming the org and space", func() {
+						response := makeInstanceProvisioningRequest(instanceID, serviceDetails)
+						Expect(response.StatusCode).To(Equal(422))
+						Expect(response.Body).To(MatchJSON(`{"description":"quota has been exceeded for organization organization-guid and space space-guid"}`))
+					})
+
+					It("uses the plan's custom quota message when it has one", func() {
+						services := fakeServiceBroker.Services()
+						services[0].Plans[0].ErrorMessages = map[string]string{
+							brokerapi.ErrorCodeQuotaExceeded: "Please contact sales to increase your quota",
+						}
+						fakeServiceBroker.Catalog = services
+						serviceDetails.PlanID = services[0].Plans[0].ID
+
+						response := makeInstanceProvisioningRequest(instanceID, serviceDetails)
+						Expect(response.StatusCode).To(Equal(422))
+						Expect(response.Body).To(MatchJSON(`{"description":"Please contact sales to increase your quota"}`))
+					})
+
+					It("uses the default message when the plan has no custom one", func() {
+						services := fakeServiceBroker.Services()
+						services[0].Plans[0].ErrorMessages = map[string]string{
+							"other_error": "Something else",
+						}
+						fakeServiceBroker.Catalog = services
+						serviceDetails.PlanID = services[0].Plans[0].ID
+
+						response := makeInstanceProvisioningRequest(instanceID, serviceDetails)
+						Expect(response.Body).To(MatchJSON(`{"description":"quota has been exceeded for organization organization-guid and space space-guid"}`))
+					})
+
+					It("logs an error distinct from the instance limit", func() {
+						makeInstanceProvisioningRequest(instanceID, serviceDetails)
+
+						Expect(lastLogLine().Message).To(ContainSubstring("provision.quota-exceeded"))
+						Expect(lastLogLine().Data["organization-guid"]).To(Equal("organization-guid"))
+						Expect(lastLogLine().Data["space-guid"]).To(Equal("space-guid"))
+					})
 				})
 
-				It("returns json with a dashboard_url field", func() {
-					response := makeInstanceProvisioningRequest(instanceID, serviceDetails)
-					Expect(response.Body).To(MatchJSON(fixture("provisioning.json")))
+				Context("when another operation is in progress", func() {
+					BeforeEach(func() {
+						fakeServiceBroker.ProvisionError = brokerapi.ErrConcurrentOperation
+					})
+
+					It("returns a 422 with the ConcurrencyError code", func() {
+						response := makeInstanceProvisioningRequest(instanceID, serviceDetails)
+						Expect(response.StatusCode).To(Equal(422))
+						Expect(response.Body).To(MatchJSON(`{
+							"error": "` + brokerapi.ErrorCodeConcurrencyError + `",
+							"description": "another operation for this service instance is in progress"
+						}`))
+					})
 				})
 
-				Context("when the instance limit has been reached", func() {
+				Context("when the service is unavailable", func() {
 					BeforeEach(func() {
-						for i := 0; i < fakeServiceBroker.InstanceLimit; i++ {
-							makeInstanceProvisioningRequest(uniqueInstanceID(), serviceDetails)
-						}
+						fakeServiceBroker.ProvisionError = brokerapi.ErrServiceUnavailable
 					})
 
-					It("returns a 500", func() {
+					It("returns a 503 with a Retry-After header", func() {
 						response := makeInstanceProvisioningRequest(instanceID, serviceDetails)
-						Expect(response.StatusCode).To(Equal(500))
+						Expect(response.StatusCode).To(Equal(503))
+						Expect(response.RawResponse.Header.Get("Retry-After")).To(Equal("30"))
 					})
 
-					It("returns json with a description field and a useful error message", func() {
+					It("returns json with a description field", func() {
 						response := makeInstanceProvisioningRequest(instanceID, serviceDetails)
-						Expect(response.Body).To(MatchJSON(fixture("instance_limit_error.json")))
+						Expect(response.Body).To(MatchJSON(`{"description":"service is temporarily unavailable, try again later"}`))
 					})
 
 					It("logs an appropriate error", func() {
 						makeInstanceProvisioningRequest(instanceID, serviceDetails)
-
-						Expect(lastLogLine().Message).To(ContainSubstring("provision.instance-limit-reached"))
-						Expect(lastLogLine().Data["error"]).To(ContainSubstring("instance limit for this service has been reached"))
+						Expect(lastLogLine().Message).To(ContainSubstring("provision.service-unavailable"))
 					})
 				})
 
@@ -260,6 +1088,36 @@ var _ = Describe("Service Broker API", func() {
 						Expect(lastLogLine().Message).To(ContainSubstring("provision.unknown-error"))
 						Expect(lastLogLine().Data["error"]).To(ContainSubstring("broker failed"))
 					})
+
+					It("tags the log with the service and plan IDs", func() {
+						serviceDetails.ID = "service-id"
+						makeInstanceProvisioningRequest(instanceID, serviceDetails)
+						Expect(lastLogLine().Data["service-id"]).To(Equal("service-id"))
+						Expect(lastLogLine().Data["plan-id"]).To(Equal("plan-id"))
+					})
+
+					It("does not tag the log with the plan name by default", func() {
+						serviceDetails.PlanID = "ABE176EE-F69F-4A96-80CE-142595CC24E3"
+						makeInstanceProvisioningRequest(instanceID, serviceDetails)
+						Expect(lastLogLine().Data).NotTo(HaveKey("plan-name"))
+					})
+
+					Context("when plan name logging is enabled", func() {
+						BeforeEach(func() {
+							brokerAPI = brokerapi.New(fakeServiceBroker, brokerLogger, credentials, brokerapi.WithPlanNameLogging())
+						})
+
+						It("tags the log with the plan name from the catalog", func() {
+							serviceDetails.PlanID = "ABE176EE-F69F-4A96-80CE-142595CC24E3"
+							makeInstanceProvisioningRequest(instanceID, serviceDetails)
+							Expect(lastLogLine().Data["plan-name"]).To(Equal("default"))
+						})
+
+						It("falls back to the plan ID when the plan is not in the catalog", func() {
+							makeInstanceProvisioningRequest(instanceID, serviceDetails)
+							Expect(lastLogLine().Data["plan-name"]).To(Equal("plan-id"))
+						})
+					})
 				})
 
 				Context("when we send invalid json", func() {
@@ -293,6 +1151,131 @@ var _ = Describe("Service Broker API", func() {
 				})
 			})
 
+			Context("when the plan is deprecated", func() {
+				BeforeEach(func() {
+					fakeServiceBroker.Catalog = []brokerapi.Service{
+						{
+							ID: "service-id",
+							Plans: []brokerapi.ServicePlan{
+								{
+									ID: "plan-id",
+									Metadata: brokerapi.ServicePlanMetadata{
+										Deprecated:         true,
+										DeprecationMessage: "use the large plan instead",
+									},
+								},
+							},
+						},
+					}
+				})
+
+				It("provisions the instance by default", func() {
+					response := makeInstanceProvisioningRequest(instanceID, serviceDetails)
+					Expect(response.StatusCode).To(Equal(201))
+				})
+
+				Context("and provisioning deprecated plans is rejected", func() {
+					BeforeEach(func() {
+						brokerAPI = brokerapi.New(fakeServiceBroker, brokerLogger, credentials, brokerapi.WithRejectDeprecatedPlanProvisioning())
+					})
+
+					It("returns a 422 with the deprecation message", func() {
+						response := makeInstanceProvisioningRequest(instanceID, serviceDetails)
+						Expect(response.StatusCode).To(Equal(422))
+						Expect(response.Body).To(MatchJSON(`{"description":"use the large plan instead"}`))
+					})
+
+					It("does not call Provision on the service broker", func() {
+						makeInstanceProvisioningRequest(instanceID, serviceDetails)
+						Expect(fakeServiceBroker.ProvisionedInstanceIDs).To(BeEmpty())
+					})
+
+					It("logs an appropriate error", func() {
+						makeInstanceProvisioningRequest(instanceID, serviceDetails)
+						Expect(lastLogLine().Message).To(ContainSubstring("provision.deprecated-plan"))
+						Expect(lastLogLine().Data["error"]).To(ContainSubstring("use the large plan instead"))
+					})
+
+					It("uses a default message when the plan has none", func() {
+						fakeServiceBroker.Catalog[0].Plans[0].Metadata.DeprecationMessage = ""
+						response := makeInstanceProvisioningRequest(instanceID, serviceDetails)
+						Expect(response.StatusCode).To(Equal(422))
+						Expect(response.Body).To(MatchJSON(`{"description":"this plan is deprecated"}`))
+					})
+				})
+			})
+
+			Context("when a resource checker is configured", func() {
+				var resourceChecker *fakes.FakeResourceChecker
+
+				BeforeEach(func() {
+					fakeServiceBroker.Catalog = []brokerapi.Service{
+						{
+							ID: "service-id",
+							Plans: []brokerapi.ServicePlan{
+								{
+									ID:             "plan-id",
+									ResourceQuotas: map[string]int{"cpu": 4, "memory_gb": 8},
+								},
+							},
+						},
+					}
+					resourceChecker = &fakes.FakeResourceChecker{}
+					brokerAPI = brokerapi.New(fakeServiceBroker, brokerLogger, credentials, brokerapi.WithResourceChecker(resourceChecker))
+				})
+
+				It("checks the plan's quotas for the organization", func() {
+					makeInstanceProvisioningRequest(instanceID, serviceDetails)
+					Expect(resourceChecker.CheckedOrganizationGUID).To(Equal("organization-guid"))
+					Expect(resourceChecker.CheckedPlanID).To(Equal("plan-id"))
+					Expect(resourceChecker.CheckedQuotas).To(Equal(map[string]int{"cpu": 4, "memory_gb": 8}))
+				})
+
+				It("provisions the instance when the quota is available", func() {
+					response := makeInstanceProvisioningRequest(instanceID, serviceDetails)
+					Expect(response.StatusCode).To(Equal(201))
+					Expect(fakeServiceBroker.ProvisionedInstanceIDs).To(ContainElement(instanceID))
+				})
+
+				Context("when a quota is exceeded", func() {
+					BeforeEach(func() {
+						resourceChecker.CheckQuotaError = brokerapi.ResourceQuotaExceededError{
+							Resource:  "memory_gb",
+							Quota:     8,
+							Requested: 10,
+						}
+					})
+
+					It("returns a 422 naming the exhausted resource", func() {
+						response := makeInstanceProvisioningRequest(instanceID, serviceDetails)
+						Expect(response.StatusCode).To(Equal(422))
+						Expect(response.Body).To(MatchJSON(`{"description":"resource quota exceeded for memory_gb: requested 10, quota 8"}`))
+					})
+
+					It("does not call Provision on the service broker", func() {
+						makeInstanceProvisioningRequest(instanceID, serviceDetails)
+						Expect(fakeServiceBroker.ProvisionedInstanceIDs).To(BeEmpty())
+					})
+
+					It("logs an appropriate error", func() {
+						makeInstanceProvisioningRequest(instanceID, serviceDetails)
+						Expect(lastLogLine().Message).To(ContainSubstring("provision.resource-quota-exceeded"))
+					})
+				})
+
+				Context("when the quota cannot be checked", func() {
+					BeforeEach(func() {
+						resourceChecker.CheckQuotaError = errors.New("quota service unavailable")
+					})
+
+					It("returns a 500", func() {
+						response := makeInstanceProvisioningRequest(instanceID, serviceDetails)
+						Expect(response.StatusCode).To(Equal(500))
+						Expect(response.Body).To(MatchJSON(`{"description":"quota service unavailable"}`))
+					})
+				})
+			})
+
 			Context("when the instance already exists", func() {
 				BeforeEach(func() {
 					makeInstanceProvisioningRequest(instanceID, serviceDetails)
@@ -346,6 +1329,29 @@ var _ = Describe("Service Broker API", func() {
 					response := makeInstanceDeprovisioningRequest(instanceID)
 					Expect(response.Body).To(MatchJSON(`{}`))
 				})
+
+				Context("and it has already been deprovisioned", func() {
+					BeforeEach(func() {
+						response := makeInstanceDeprovisioningRequest(instanceID)
+						Expect(response.StatusCode).To(Equal(200))
+					})
+
+					It("returns a 410 every time", func() {
+						response := makeInstanceDeprovisioningRequest(instanceID)
+						Expect(response.StatusCode).To(Equal(410))
+						Expect(response.Body).To(MatchJSON(`{}`))
+
+						response = makeInstanceDeprovisioningRequest(instanceID)
+						Expect(response.StatusCode).To(Equal(410))
+						Expect(response.Body).To(MatchJSON(`{}`))
+					})
+
+					It("logs an appropriate error", func() {
+						makeInstanceDeprovisioningRequest(instanceID)
+						Expect(lastLogLine().Message).To(ContainSubstring("deprovision.instance-missing"))
+						Expect(lastLogLine().Data["error"]).To(ContainSubstring("instance has already been deprovisioned"))
+					})
+				})
 			})
 
 			Context("when the instance does not exist", func() {
@@ -367,6 +1373,54 @@ var _ = Describe("Service Broker API", func() {
 					Expect(lastLogLine().Message).To(ContainSubstring("deprovision.instance-missing"))
 					Expect(lastLogLine().Data["error"]).To(ContainSubstring("instance does not exist"))
 				})
+
+				It("does not tag the log with service or plan IDs it was not given", func() {
+					makeInstanceDeprovisioningRequest(uniqueInstanceID())
+					Expect(lastLogLine().Data).NotTo(HaveKey("service-id"))
+					Expect(lastLogLine().Data).NotTo(HaveKey("plan-id"))
+				})
+
+				It("tags the log with the service and plan IDs from the query", func() {
+					makeInstanceDeprovisioningRequest(uniqueInstanceID() + "?service_id=service-id&plan_id=plan-id")
+					Expect(lastLogLine().Data["service-id"]).To(Equal("service-id"))
+					Expect(lastLogLine().Data["plan-id"]).To(Equal("plan-id"))
+				})
+			})
+
+			Context("when the broker signals the instance is gone with a description", func() {
+				BeforeEach(func() {
+					fakeServiceBroker.DeprovisionError = brokerapi.InstanceGoneError{
+						Description: "instance already deleted at 2015-04-01T10:00:00Z",
+					}
+				})
+
+				It("returns a 410", func() {
+					response := makeInstanceDeprovisioningRequest(uniqueInstanceID())
+					Expect(response.StatusCode).To(Equal(410))
+				})
+
+				It("returns the custom description", func() {
+					response := makeInstanceDeprovisioningRequest(uniqueInstanceID())
+					Expect(response.Body).To(MatchJSON(`{"description":"instance already deleted at 2015-04-01T10:00:00Z"}`))
+				})
+
+				It("logs an appropriate error", func() {
+					makeInstanceDeprovisioningRequest(uniqueInstanceID())
+					Expect(lastLogLine().Message).To(ContainSubstring("deprovision.instance-missing"))
+					Expect(lastLogLine().Data["error"]).To(ContainSubstring("instance already deleted"))
+				})
+			})
+
+			Context("when the broker signals the instance is gone without a description", func() {
+				BeforeEach(func() {
+					fakeServiceBroker.DeprovisionError = brokerapi.InstanceGoneError{}
+				})
+
+				It("returns an empty JSON object", func() {
+					response := makeInstanceDeprovisioningRequest(uniqueInstanceID())
+					Expect(response.StatusCode).To(Equal(410))
+					Expect(response.Body).To(MatchJSON(`{}`))
+				})
 			})
 
 			Context("when instance deprovisioning fails", func() {
@@ -407,12 +1461,12 @@ var _ = Describe("Service Broker API", func() {
 	})
 
 	Describe("binding lifecycle endpoint", func() {
-		makeBindingRequest := func(instanceID string, bindingID string) *testflight.Response {
+		makeBindingRequestWithBody := func(instanceID string, bindingID string, body string) *testflight.Response {
 			response := &testflight.Response{}
 			testflight.WithServer(brokerAPI, func(r *testflight.Requester) {
 				path := fmt.Sprintf("/v2/service_instances/%s/service_bindings/%s",
 					instanceID, bindingID)
-				request, _ := http.NewRequest("PUT", path, strings.NewReader(""))
+				request, _ := http.NewRequest("PUT", path, strings.NewReader(body))
 				request.Header.Add("Content-Type", "application/json")
 				request.SetBasicAuth("username", "password")
 
@@ -421,6 +1475,10 @@ var _ = Describe("Service Broker API", func() {
 			return response
 		}
 
+		makeBindingRequest := func(instanceID string, bindingID string) *testflight.Response {
+			return makeBindingRequestWithBody(instanceID, bindingID, "")
+		}
+
 		Describe("binding", func() {
 			Context("when the associated instance exists", func() {
 				It("calls Bind on the service broker with the instance and binding ids", func() {
@@ -440,6 +1498,55 @@ var _ = Describe("Service Broker API", func() {
 					response := makeBindingRequest(uniqueInstanceID(), uniqueBindingID())
 					Expect(response.StatusCode).To(Equal(201))
 				})
+
+				Context("when Bind returns a CredentialsFunc", func() {
+					var calls int
+
+					BeforeEach(func() {
+						calls = 0
+						fakeServiceBroker.Credentials = brokerapi.CredentialsFunc(func() (interface{}, error) {
+							calls++
+							return map[string]string{"token": "short-lived"}, nil
+						})
+					})
+
+					It("calls it once and returns its credentials", func() {
+						response := makeBindingRequest(uniqueInstanceID(), uniqueBindingID())
+						Expect(response.StatusCode).To(Equal(201))
+						Expect(response.Body).To(MatchJSON(`{"credentials":{"token":"short-lived"}}`))
+						Expect(calls).To(Equal(1))
+					})
+
+					It("does not call it when binding fails", func() {
+						fakeServiceBroker.BindError = brokerapi.ErrBindingAlreadyExists
+						response := makeBindingRequest(uniqueInstanceID(), uniqueBindingID())
+						Expect(response.StatusCode).To(Equal(409))
+						Expect(calls).To(Equal(0))
+					})
+
+					It("returns a 500 when it fails", func() {
+						fakeServiceBroker.Credentials = brokerapi.CredentialsFunc(func() (interface{}, error) {
+							return nil, errors.New("could not mint credentials")
+						})
+						response := makeBindingRequest(uniqueInstanceID(), uniqueBindingID())
+						Expect(response.StatusCode).To(Equal(500))
+						Expect(response.Body).To(MatchJSON(`{"description":"could not mint credentials"}`))
+					})
+				})
+
+				It("returns the credentials under a configured key", func() {
+					brokerAPI = brokerapi.New(fakeServiceBroker, brokerLogger, credentials, brokerapi.WithCredentialsKey("secrets"))
+					response := makeBindingRequest(uniqueInstanceID(), uniqueBindingID())
+					Expect(response.StatusCode).To(Equal(201))
+					Expect(response.Body).To(MatchJSON(`{
+						"secrets": {
+							"host": "127.0.0.1",
+							"port": 3000,
+							"username": "batman",
+							"password": "robin"
+						}
+					}`))
+				})
 			})
 
 			Context("when the associated instance does not exist", func() {
@@ -494,6 +1601,204 @@ var _ = Describe("Service Broker API", func() {
 				})
 			})
 
+			Context("when bindability is checked against the catalog", func() {
+				notBindable := false
+				bindablePlan := true
+
+				BeforeEach(func() {
+					brokerAPI = brokerapi.New(fakeServiceBroker, brokerLogger, credentials, brokerapi.WithBindableCheck())
+					fakeServiceBroker.Catalog = []brokerapi.Service{
+						{
+							ID:       "bindable-service-id",
+							Bindable: true,
+							Plans: []brokerapi.ServicePlan{
+								{ID: "bindable-plan-id"},
+								{ID: "unbindable-plan-id", Bindable: &notBindable},
+							},
+						},
+						{
+							ID: "unbindable-service-id",
+							Plans: []brokerapi.ServicePlan{
+								{ID: "default-plan-id"},
+								{ID: "overriding-plan-id", Bindable: &bindablePlan},
+							},
+						},
+					}
+				})
+
+				bind := func(serviceID, planID string) *testflight.Response {
+					body := `{"service_id":"` + serviceID + `","plan_id":"` + planID + `"}`
+					return makeBindingRequestWithBody(uniqueInstanceID(), uniqueBindingID(), body)
+				}
+
+				It("binds plans of a bindable service", func() {
+					Expect(bind("bindable-service-id", "bindable-plan-id").StatusCode).To(Equal(201))
+				})
+
+				It("binds a bindable plan of a non-bindable service", func() {
+					Expect(bind("unbindable-service-id", "overriding-plan-id").StatusCode).To(Equal(201))
+				})
+
+				It("rejects a non-bindable plan of a bindable service", func() {
+					response := bind("bindable-service-id", "unbindable-plan-id")
+					Expect(response.StatusCode).To(Equal(400))
+					Expect(response.Body).To(MatchJSON(`{"description":"service plan is not bindable"}`))
+					Expect(fakeServiceBroker.BoundBindingIDs).To(BeEmpty())
+				})
+
+				It("rejects plans of a non-bindable service that don't override it", func() {
+					Expect(bind("unbindable-service-id", "default-plan-id").StatusCode).To(Equal(400))
+				})
+
+				It("falls back to the service when the plan is unknown", func() {
+					Expect(bind("unbindable-service-id", "unknown-plan-id").StatusCode).To(Equal(400))
+					Expect(bind("bindable-service-id", "unknown-plan-id").StatusCode).To(Equal(201))
+				})
+
+				It("matches the plan within the requested service", func() {
+					Expect(bind("unbindable-service-id", "bindable-plan-id").StatusCode).To(Equal(400))
+					Expect(bind("bindable-service-id", "overriding-plan-id").StatusCode).To(Equal(201))
+				})
+
+				It("passes through services that are not in the catalog", func() {
+					Expect(bind("unknown-service-id", "unbindable-plan-id").StatusCode).To(Equal(201))
+				})
+			})
+
+			Context("when bindability is set in the catalog but not checked", func() {
+				BeforeEach(func() {
+					fakeServiceBroker.Catalog = []brokerapi.Service{
+						{
+							ID:    "unbindable-service-id",
+							Plans: []brokerapi.ServicePlan{{ID: "plan-id"}},
+						},
+					}
+				})
+
+				It("binds plans of a non-bindable service", func() {
+					body := `{"service_id":"unbindable-service-id","plan_id":"plan-id"}`
+					response := makeBindingRequestWithBody(uniqueInstanceID(), uniqueBindingID(), body)
+					Expect(response.StatusCode).To(Equal(201))
+				})
+			})
+
+			Context("when the instance's plan is deprecated", func() {
+				BeforeEach(func() {
+					fakeServiceBroker.Catalog = []brokerapi.Service{
+						{
+							ID: "service-id",
+							Plans: []brokerapi.ServicePlan{
+								{
+									ID:       "plan-id",
+									Metadata: brokerapi.ServicePlanMetadata{Deprecated: true},
+								},
+							},
+						},
+					}
+				})
+
+				It("binds successfully and logs a warning", func() {
+					body := `{"service_id":"service-id","plan_id":"plan-id"}`
+					response := makeBindingRequestWithBody(uniqueInstanceID(), uniqueBindingID(), body)
+					Expect(response.StatusCode).To(Equal(201))
+
+					Expect(lastLogLine().Message).To(ContainSubstring("bind.deprecated-plan"))
+					Expect(lastLogLine().LogLevel).To(Equal(lager.INFO))
+					Expect(lastLogLine().Data["plan-id"]).To(Equal("plan-id"))
+				})
+			})
+
+			Context("when the request body is invalid JSON", func() {
+				It("ignores the body", func() {
+					bindingID := uniqueBindingID()
+					response := makeBindingRequestWithBody(uniqueInstanceID(), bindingID, "{{{{{")
+					Expect(response.StatusCode).To(Equal(201))
+					Expect(fakeServiceBroker.BoundBindingIDs).To(ContainElement(bindingID))
+				})
+
+				Context("and services require an exclusive bind_resource", func() {
+					BeforeEach(func() {
+						brokerAPI = brokerapi.New(fakeServiceBroker, brokerLogger, credentials, brokerapi.WithExclusiveBindResource("service-id"))
+					})
+
+					It("returns a 422", func() {
+						response := makeBindingRequestWithBody(uniqueInstanceID(), uniqueBindingID(), "{{{{{")
+						Expect(response.StatusCode).To(Equal(422))
+					})
+
+					It("does not call Bind on the service broker", func() {
+						makeBindingRequestWithBody(uniqueInstanceID(), uniqueBindingID(), "{{{{{")
+						Expect(fakeServiceBroker.BoundBindingIDs).To(BeEmpty())
+					})
+
+					It("accepts an empty body", func() {
+						response := makeBindingRequest(uniqueInstanceID(), uniqueBindingID())
+						Expect(response.StatusCode).To(Equal(201))
+					})
+				})
+			})
+
+			Context("when the service requires an exclusive bind_resource", func() {
+				const appOnly = `{"service_id":"service-id","bind_resource":{"app_guid":"app-guid"}}`
+				const routeOnly = `{"service_id":"service-id","bind_resource":{"route":"route.example.com"}}`
+				const both = `{"service_id":"service-id","bind_resource":{"app_guid":"app-guid","route":"route.example.com"}}`
+
+				BeforeEach(func() {
+					brokerAPI = brokerapi.New(fakeServiceBroker, brokerLogger, credentials, brokerapi.WithExclusiveBindResource("service-id"))
+				})
+
+				It("accepts a bind_resource with only an app_guid", func() {
+					response := makeBindingRequestWithBody(uniqueInstanceID(), uniqueBindingID(), appOnly)
+					Expect(response.StatusCode).To(Equal(201))
+				})
+
+				It("accepts a bind_resource with only a route", func() {
+					response := makeBindingRequestWithBody(uniqueInstanceID(), uniqueBindingID(), routeOnly)
+					Expect(response.StatusCode).To(Equal(201))
+				})
+
+				It("rejects a bind_resource with both an app_guid and a route", func() {
+					response := makeBindingRequestWithBody(uniqueInstanceID(), uniqueBindingID(), both)
+					Expect(response.StatusCode).To(Equal(400))
+					Expect(response.Body).To(MatchJSON(`{"description":"bind_resource must not include both app_guid and route"}`))
+					Expect(fakeServiceBroker.BoundBindingIDs).To(BeEmpty())
+				})
+
+				It("accepts both for services that do not require it", func() {
+					body := `{"service_id":"other-service-id","bind_resource":{"app_guid":"app-guid","route":"route.example.com"}}`
+					response := makeBindingRequestWithBody(uniqueInstanceID(), uniqueBindingID(), body)
+					Expect(response.StatusCode).To(Equal(201))
+				})
+			})
+
+			Context("when another operation is in progress", func() {
+				BeforeEach(func() {
+					fakeServiceBroker.BindError = brokerapi.ErrConcurrentOperation
+				})
+
+				It("returns a 422 with the ConcurrencyError code", func() {
+					response := makeBindingRequest(uniqueInstanceID(), uniqueBindingID())
+					Expect(response.StatusCode).To(Equal(422))
+
+					var errorResponse brokerapi.ErrorResponse
+					err := json.Unmarshal([]byte(response.Body), &errorResponse)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(errorResponse.Error).To(Equal(brokerapi.ErrorCodeConcurrencyError))
+				})
+			})
+
+			Context("when the service is unavailable", func() {
+				BeforeEach(func() {
+					fakeServiceBroker.BindError = brokerapi.ErrServiceUnavailable
+				})
+
+				It("returns a 503 with a Retry-After header", func() {
+					response := makeBindingRequest(uniqueInstanceID(), uniqueBindingID())
+					Expect(response.StatusCode).To(Equal(503))
+					Expect(response.RawResponse.Header.Get("Retry-After")).To(Equal("30"))
+				})
+			})
+
 			Context("when the binding returns an error", func() {
 				BeforeEach(func() {
 					fakeServiceBroker.BindError = errors.New("random error")
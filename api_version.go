@@ -0,0 +1,14 @@
+package brokerapi
+
+import "net/http"
+
+const DefaultAPIVersion = "2.4"
+
+const apiVersionHeader = "X-Broker-API-Version"
+
+func wrapAPIVersion(handler http.Handler, version string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set(apiVersionHeader, version)
+		handler.ServeHTTP(w, req)
+	})
+}
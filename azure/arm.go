@@ -0,0 +1,72 @@
+package azure
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+
+	"github.com/pivotal-cf/brokerapi"
+)
+
+const ARMParametersSchema = "https://schema.management.azure.com/schemas/2019-04-01/deploymentParameters.json#"
+const ARMContentVersion = "1.0.0.0"
+
+const serviceNameParameter = "serviceName"
+
+var errCredentialsNotObject = errors.New("credentials must serialize to a JSON object")
+
+type ARMParameter struct {
+	Value interface{} `json:"value"`
+}
+
+type ARMParametersFile struct {
+	Schema         string                  `json:"$schema"`
+	ContentVersion string                  `json:"contentVersion"`
+	Parameters     map[string]ARMParameter `json:"parameters"`
+}
+
+func CredentialsToARMParameters(credentials interface{}) (map[string]ARMParameter, error) {
+	data, err := json.Marshal(credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var fields map[string]interface{}
+	if err := decoder.Decode(&fields); err != nil || fields == nil {
+		return nil, errCredentialsNotObject
+	}
+
+	parameters := map[string]ARMParameter{}
+	for name, value := range fields {
+		parameters[name] = ARMParameter{Value: value}
+	}
+	return parameters, nil
+}
+
+func ARMParametersToCredentials(parameters map[string]ARMParameter) map[string]interface{} {
+	credentials := map[string]interface{}{}
+	for name, parameter := range parameters {
+		credentials[name] = parameter.Value
+	}
+	return credentials
+}
+
+func GenerateARMTemplate(service brokerapi.Service, binding brokerapi.BindingResponse) ([]byte, error) {
+	parameters, err := CredentialsToARMParameters(binding.Credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, found := parameters[serviceNameParameter]; !found {
+		parameters[serviceNameParameter] = ARMParameter{Value: service.Name}
+	}
+
+	return json.MarshalIndent(ARMParametersFile{
+		Schema:         ARMParametersSchema,
+		ContentVersion: ARMContentVersion,
+		Parameters:     parameters,
+	}, "", "  ")
+}
@@ -0,0 +1,105 @@
+package azure_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/brokerapi"
+	"github.com/pivotal-cf/brokerapi/azure"
+)
+
+type databaseCredentials struct {
+	Hostname string `json:"hostname"`
+	Port     int    `json:"port"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+var _ = Describe("ARM parameters", func() {
+	Describe("CredentialsToARMParameters", func() {
+		It("wraps each credential in a value object", func() {
+			parameters, err := azure.CredentialsToARMParameters(databaseCredentials{
+				Hostname: "db.example.com",
+				Port:     5432,
+				Username: "admin",
+				Password: "secret",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			data, err := json.Marshal(parameters)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(MatchJSON(`{
+				"hostname": {"value": "db.example.com"},
+				"port": {"value": 5432},
+				"username": {"value": "admin"},
+				"password": {"value": "secret"}
+			}`))
+		})
+
+		It("round-trips a connection string", func() {
+			credentials := map[string]interface{}{
+				"connectionString": "Server=tcp:db.example.com,1433;Database=orders;User ID=admin;Password=p@ss;",
+			}
+
+			parameters, err := azure.CredentialsToARMParameters(credentials)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(azure.ARMParametersToCredentials(parameters)).To(Equal(credentials))
+		})
+
+		It("round-trips hostnames and ports", func() {
+			original := databaseCredentials{Hostname: "db.example.com", Port: 3306}
+
+			parameters, err := azure.CredentialsToARMParameters(original)
+			Expect(err).NotTo(HaveOccurred())
+
+			data, err := json.Marshal(azure.ARMParametersToCredentials(parameters))
+			Expect(err).NotTo(HaveOccurred())
+
+			var roundTripped databaseCredentials
+			err = json.Unmarshal(data, &roundTripped)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(roundTripped).To(Equal(original))
+		})
+
+		It("keeps nested credentials as object values", func() {
+			parameters, err := azure.CredentialsToARMParameters(map[string]interface{}{
+				"tls": map[string]interface{}{"enabled": true},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(parameters["tls"].Value).To(Equal(map[string]interface{}{"enabled": true}))
+		})
+
+		It("returns an error for credentials that are not an object", func() {
+			_, err := azure.CredentialsToARMParameters("just-a-string")
+			Expect(err).To(MatchError("credentials must serialize to a JSON object"))
+
+			_, err = azure.CredentialsToARMParameters(nil)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("GenerateARMTemplate", func() {
+		It("produces a deployment parameters file", func() {
+			service := brokerapi.Service{Name: "p-postgres"}
+			binding := brokerapi.BindingResponse{
+				Credentials: databaseCredentials{Hostname: "db.example.com", Port: 5432},
+			}
+
+			template, err := azure.GenerateARMTemplate(service, binding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(template).To(MatchJSON(`{
+				"$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentParameters.json#",
+				"contentVersion": "1.0.0.0",
+				"parameters": {
+					"hostname": {"value": "db.example.com"},
+					"port": {"value": 5432},
+					"username": {"value": ""},
+					"password": {"value": ""},
+					"serviceName": {"value": "p-postgres"}
+				}
+			}`))
+		})
+	})
+})
@@ -0,0 +1,20 @@
+package brokerapi
+
+import "errors"
+
+var errBindResourceNotExclusive = errors.New("bind_resource must not include both app_guid and route")
+
+func validateBindResource(bindDetails BindDetails, exclusiveServiceIDs []string) error {
+	resource := bindDetails.BindResource
+	if resource == nil || resource.AppGUID == "" || resource.Route == "" {
+		return nil
+	}
+
+	for _, serviceID := range exclusiveServiceIDs {
+		if serviceID == bindDetails.ServiceID {
+			return errBindResourceNotExclusive
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,28 @@
+package brokerapi
+
+import "errors"
+
+const notBindableErrorKey = "not-bindable"
+
+var errNotBindable = errors.New("service plan is not bindable")
+
+func (service Service) PlanBindable(plan ServicePlan) bool {
+	if plan.Bindable != nil {
+		return *plan.Bindable
+	}
+	return service.Bindable
+}
+
+func bindable(services []Service, serviceID, planID string) bool {
+	service, found := findService(services, serviceID)
+	if !found {
+		return true
+	}
+
+	for _, plan := range service.Plans {
+		if plan.ID == planID {
+			return service.PlanBindable(plan)
+		}
+	}
+	return service.Bindable
+}
@@ -0,0 +1,124 @@
+package brokerapi
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pivotal-golang/lager"
+)
+
+const WebhookEventBindingExpired = "binding.expired"
+
+const bindingExpiredLogKey = "binding-expired"
+const expireBindingErrorKey = "expire-binding-failed"
+
+const expireBindingRetries = 3
+
+type BindingExpiry struct {
+	mutex      sync.Mutex
+	timers     map[string]*time.Timer
+	retryDelay time.Duration
+	stopped    bool
+	done       chan struct{}
+}
+
+func NewBindingExpiry(retryDelay time.Duration) *BindingExpiry {
+	return &BindingExpiry{
+		timers:     map[string]*time.Timer{},
+		retryDelay: retryDelay,
+		done:       make(chan struct{}),
+	}
+}
+
+func (expiry *BindingExpiry) Stop() {
+	expiry.mutex.Lock()
+	defer expiry.mutex.Unlock()
+
+	for key, timer := range expiry.timers {
+		timer.Stop()
+		delete(expiry.timers, key)
+	}
+	if !expiry.stopped {
+		close(expiry.done)
+	}
+	expiry.stopped = true
+}
+
+func (expiry *BindingExpiry) schedule(instanceID, bindingID string, ttl time.Duration, expire func()) {
+	expiry.mutex.Lock()
+	defer expiry.mutex.Unlock()
+
+	if expiry.stopped {
+		return
+	}
+
+	key := bindingKey(instanceID, bindingID)
+	if timer, ok := expiry.timers[key]; ok {
+		timer.Stop()
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(ttl, func() {
+		expiry.mutex.Lock()
+		current := expiry.timers[key] == timer
+		if current {
+			delete(expiry.timers, key)
+		}
+		expiry.mutex.Unlock()
+
+		if current {
+			expire()
+		}
+	})
+	expiry.timers[key] = timer
+}
+
+func (expiry *BindingExpiry) cancel(instanceID, bindingID string) {
+	expiry.mutex.Lock()
+	defer expiry.mutex.Unlock()
+
+	key := bindingKey(instanceID, bindingID)
+	if timer, ok := expiry.timers[key]; ok {
+		timer.Stop()
+		delete(expiry.timers, key)
+	}
+}
+
+func bindingKey(instanceID, bindingID string) string {
+	return instanceID + "/" + bindingID
+}
+
+func expireBinding(serviceBroker ServiceBroker, instanceID, bindingID string, bindDetails BindDetails, expiry *BindingExpiry, logger lager.Logger, notifier *eventNotifier) func() {
+	return func() {
+		delay := expiry.retryDelay
+		for attempt := 0; ; attempt++ {
+			err := serviceBroker.Unbind(instanceID, bindingID)
+			if err == nil || err == ErrBindingDoesNotExist || err == ErrInstanceDoesNotExist {
+				break
+			}
+
+			logger.Error(expireBindingErrorKey, err, lager.Data{
+				"attempt": attempt + 1,
+			})
+			if attempt == expireBindingRetries {
+				return
+			}
+
+			select {
+			case <-time.After(delay):
+			case <-expiry.done:
+				return
+			}
+			delay *= 2
+		}
+
+		logger.Info(bindingExpiredLogKey)
+		notifier.notify(WebhookEvent{
+			EventType:  WebhookEventBindingExpired,
+			InstanceID: instanceID,
+			BindingID:  bindingID,
+			ServiceID:  bindDetails.ServiceID,
+			PlanID:     bindDetails.PlanID,
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package brokerapi_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/brokerapi"
+	"github.com/pivotal-cf/brokerapi/fakes"
+)
+
+type expiringBroker struct {
+	fakes.FakeServiceBroker
+
+	mutex          sync.Mutex
+	unbindFailures int
+	unbinds        []string
+}
+
+func (broker *expiringBroker) Unbind(instanceID, bindingID string) error {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+
+	broker.unbinds = append(broker.unbinds, bindingID)
+	if len(broker.unbinds) <= broker.unbindFailures {
+		return errors.New("unbind failed")
+	}
+	return nil
+}
+
+func (broker *expiringBroker) unbindCount() int {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+
+	return len(broker.unbinds)
+}
+
+type channelEmitter chan brokerapi.WebhookEvent
+
+func (emitter channelEmitter) Emit(event brokerapi.WebhookEvent) error {
+	emitter <- event
+	return nil
+}
+
+var _ = Describe("Binding expiry", func() {
+	var serviceBroker *expiringBroker
+	var expiry *brokerapi.BindingExpiry
+	var events channelEmitter
+	var brokerAPI http.Handler
+	var credentials = brokerapi.BrokerCredentials{
+		Username: "username",
+		Password: "password",
+	}
+
+	makeRequest := func(method, bindingID, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(method, "/v2/service_instances/instance-id/service_bindings/"+bindingID, strings.NewReader(body))
+		request.SetBasicAuth(credentials.Username, credentials.Password)
+		brokerAPI.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	bindWithTTL := func(bindingID, body string) {
+		Expect(makeRequest("PUT", bindingID, body).Code).To(Equal(201))
+	}
+
+	const withTTL = `{"service_id":"service-id","plan_id":"plan-id","binding_ttl":1}`
+	const withoutTTL = `{"service_id":"service-id","plan_id":"plan-id"}`
+
+	BeforeEach(func() {
+		serviceBroker = &expiringBroker{}
+		expiry = brokerapi.NewBindingExpiry(time.Millisecond)
+		events = make(channelEmitter, 10)
+		brokerLogger := lagertest.NewTestLogger("broker-api")
+		brokerAPI = brokerapi.New(serviceBroker, brokerLogger, credentials, brokerapi.WithBindingExpiry(expiry), brokerapi.WithEventEmitter(events))
+	})
+
+	AfterEach(func() {
+		expiry.Stop()
+	})
+
+	It("unbinds the binding when its TTL in seconds elapses", func() {
+		bindWithTTL("binding-id", withTTL)
+
+		Consistently(serviceBroker.unbindCount, 500*time.Millisecond).Should(Equal(0))
+		Eventually(serviceBroker.unbindCount, 2*time.Second).Should(Equal(1))
+		Expect(serviceBroker.unbinds).To(Equal([]string{"binding-id"}))
+	})
+
+	It("emits a binding.expired event", func() {
+		bindWithTTL("binding-id", withTTL)
+
+		var event brokerapi.WebhookEvent
+		Eventually(events, 2*time.Second).Should(Receive(&event))
+		Expect(event.EventType).To(Equal("binding.expired"))
+		Expect(event.InstanceID).To(Equal("instance-id"))
+		Expect(event.BindingID).To(Equal("binding-id"))
+		Expect(event.ServiceID).To(Equal("service-id"))
+		Expect(event.PlanID).To(Equal("plan-id"))
+	})
+
+	It("does not expire bindings without a TTL", func() {
+		bindWithTTL("binding-id", withoutTTL)
+
+		Consistently(serviceBroker.unbindCount, 1500*time.Millisecond).Should(Equal(0))
+	})
+
+	It("does not expire bindings that were already unbound", func() {
+		bindWithTTL("binding-id", withTTL)
+		Expect(makeRequest("DELETE", "binding-id", "").Code).To(Equal(200))
+
+		Consistently(serviceBroker.unbindCount, 1500*time.Millisecond).Should(Equal(1))
+	})
+
+	It("does not expire bindings once stopped", func() {
+		bindWithTTL("binding-id", withTTL)
+		expiry.Stop()
+
+		Consistently(serviceBroker.unbindCount, 1500*time.Millisecond).Should(Equal(0))
+	})
+
+	Context("when unbinding fails", func() {
+		It("retries until the unbind succeeds", func() {
+			serviceBroker.unbindFailures = 2
+			bindWithTTL("binding-id", withTTL)
+
+			Eventually(events, 2*time.Second).Should(Receive())
+			Expect(serviceBroker.unbindCount()).To(Equal(3))
+		})
+
+		It("gives up after three retries", func() {
+			serviceBroker.unbindFailures = 10
+			bindWithTTL("binding-id", withTTL)
+
+			Eventually(serviceBroker.unbindCount, 2*time.Second).Should(Equal(4))
+			Consistently(serviceBroker.unbindCount, 50*time.Millisecond).Should(Equal(4))
+			Expect(events).NotTo(Receive())
+		})
+
+		It("stops retrying once stopped", func() {
+			expiry = brokerapi.NewBindingExpiry(time.Hour)
+			brokerAPI = brokerapi.New(serviceBroker, lagertest.NewTestLogger("broker-api"), credentials, brokerapi.WithBindingExpiry(expiry), brokerapi.WithEventEmitter(events))
+			serviceBroker.unbindFailures = 10
+			bindWithTTL("binding-id", withTTL)
+
+			Eventually(serviceBroker.unbindCount, 2*time.Second).Should(Equal(1))
+			expiry.Stop()
+
+			Consistently(serviceBroker.unbindCount, 100*time.Millisecond).Should(Equal(1))
+			Expect(events).NotTo(Receive())
+		})
+	})
+})
@@ -0,0 +1,13 @@
+package brokerapitest_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestBrokerAPITest(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Broker API Test Helpers Suite")
+}
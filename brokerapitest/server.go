@@ -0,0 +1,24 @@
+package brokerapitest
+
+import (
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/pivotal-cf/brokerapi"
+	"github.com/pivotal-golang/lager"
+)
+
+var Credentials = brokerapi.BrokerCredentials{
+	Username: "username",
+	Password: "password",
+}
+
+func StartServer(broker brokerapi.ServiceBroker, opts ...brokerapi.Option) (string, func()) {
+	logger := lager.NewLogger("brokerapitest")
+	server := httptest.NewServer(brokerapi.New(broker, logger, Credentials, opts...))
+
+	baseURL, _ := url.Parse(server.URL)
+	baseURL.User = url.UserPassword(Credentials.Username, Credentials.Password)
+
+	return baseURL.String(), server.Close
+}
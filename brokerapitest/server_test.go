@@ -0,0 +1,63 @@
+package brokerapitest_test
+
+import (
+	"net/http"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/brokerapi"
+	"github.com/pivotal-cf/brokerapi/brokerapitest"
+	"github.com/pivotal-cf/brokerapi/fakes"
+)
+
+var _ = Describe("StartServer", func() {
+	var fakeServiceBroker *fakes.FakeServiceBroker
+	var baseURL string
+	var stop func()
+
+	doRequest := func(method, path, body string) *http.Response {
+		request, err := http.NewRequest(method, baseURL+path, strings.NewReader(body))
+		Expect(err).NotTo(HaveOccurred())
+
+		response, err := http.DefaultClient.Do(request)
+		Expect(err).NotTo(HaveOccurred())
+		response.Body.Close()
+		return response
+	}
+
+	BeforeEach(func() {
+		fakeServiceBroker = &fakes.FakeServiceBroker{InstanceLimit: 3}
+		baseURL, stop = brokerapitest.StartServer(fakeServiceBroker)
+	})
+
+	AfterEach(func() {
+		stop()
+	})
+
+	It("provisions and deprovisions through the running server", func() {
+		response := doRequest("PUT", "/v2/service_instances/instance-id", `{"service_id":"service-id","plan_id":"plan-id"}`)
+		Expect(response.StatusCode).To(Equal(201))
+		Expect(fakeServiceBroker.ProvisionedInstanceIDs).To(Equal([]string{"instance-id"}))
+
+		response = doRequest("DELETE", "/v2/service_instances/instance-id", "")
+		Expect(response.StatusCode).To(Equal(200))
+		Expect(fakeServiceBroker.DeprovisionedInstanceIDs).To(Equal([]string{"instance-id"}))
+	})
+
+	It("applies the given options", func() {
+		stop()
+		baseURL, stop = brokerapitest.StartServer(fakeServiceBroker, brokerapi.WithAPIVersion("2.5"))
+
+		response := doRequest("GET", "/v2/catalog", "")
+		Expect(response.Header.Get("X-Broker-API-Version")).To(Equal("2.5"))
+	})
+
+	It("stops accepting requests once stopped", func() {
+		stop()
+
+		_, err := http.Get(baseURL + "/v2/catalog")
+		Expect(err).To(HaveOccurred())
+	})
+})
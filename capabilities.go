@@ -0,0 +1,41 @@
+package brokerapi
+
+import "net/http"
+
+const capabilitiesAllow = "GET, PUT, PATCH, DELETE"
+
+type CapabilitiesResponse struct {
+	APIVersions []string             `json:"api_versions"`
+	Endpoints   []EndpointCapability `json:"endpoints"`
+}
+
+type EndpointCapability struct {
+	Operation   string `json:"operation"`
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	Implemented bool   `json:"implemented"`
+	Async       bool   `json:"async"`
+}
+
+func capabilities(options options) http.HandlerFunc {
+	response := CapabilitiesResponse{
+		APIVersions: []string{options.apiVersion},
+		Endpoints: []EndpointCapability{
+			{Operation: "catalog", Method: "GET", Path: "/v2/catalog", Implemented: true},
+			{Operation: provisionLogKey, Method: "PUT", Path: "/v2/service_instances/:instance_id", Implemented: true},
+			{Operation: "update", Method: "PATCH", Path: "/v2/service_instances/:instance_id"},
+			{Operation: deprovisionLogKey, Method: "DELETE", Path: "/v2/service_instances/:instance_id", Implemented: true},
+			{Operation: "last_operation", Method: "GET", Path: "/v2/service_instances/:instance_id/last_operation"},
+			{Operation: restoreLogKey, Method: "POST", Path: "/v2/service_instances/:instance_id/restore", Implemented: options.restoreSupport},
+			{Operation: tagLogKey, Method: "POST", Path: "/v2/service_instances/:instance_id/tags", Implemented: options.taggingSupport},
+			{Operation: untagLogKey, Method: "DELETE", Path: "/v2/service_instances/:instance_id/tags/:key", Implemented: options.taggingSupport},
+			{Operation: bindLogKey, Method: "PUT", Path: "/v2/service_instances/:instance_id/service_bindings/:binding_id", Implemented: true},
+			{Operation: unbindLogKey, Method: "DELETE", Path: "/v2/service_instances/:instance_id/service_bindings/:binding_id", Implemented: true},
+		},
+	}
+
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Allow", capabilitiesAllow)
+		respond(w, http.StatusOK, response)
+	}
+}
@@ -0,0 +1,121 @@
+package brokerapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/brokerapi"
+	"github.com/pivotal-cf/brokerapi/fakes"
+)
+
+var _ = Describe("Capabilities endpoint", func() {
+	var brokerAPI http.Handler
+	var credentials = brokerapi.BrokerCredentials{
+		Username: "username",
+		Password: "password",
+	}
+
+	newBrokerAPI := func(opts ...brokerapi.Option) http.Handler {
+		brokerLogger := lagertest.NewTestLogger("broker-api")
+		return brokerapi.New(&fakes.FakeServiceBroker{}, brokerLogger, credentials, opts...)
+	}
+
+	makeOptionsRequest := func() *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("OPTIONS", "/v2", nil)
+		request.SetBasicAuth(credentials.Username, credentials.Password)
+		brokerAPI.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	capabilities := func(response *httptest.ResponseRecorder) brokerapi.CapabilitiesResponse {
+		var capabilities brokerapi.CapabilitiesResponse
+		err := json.Unmarshal(response.Body.Bytes(), &capabilities)
+		Expect(err).NotTo(HaveOccurred())
+		return capabilities
+	}
+
+	endpoint := func(capabilities brokerapi.CapabilitiesResponse, operation string) brokerapi.EndpointCapability {
+		for _, endpoint := range capabilities.Endpoints {
+			if endpoint.Operation == operation {
+				return endpoint
+			}
+		}
+		Fail("no endpoint for operation " + operation)
+		return brokerapi.EndpointCapability{}
+	}
+
+	Context("when the endpoint is enabled", func() {
+		BeforeEach(func() {
+			brokerAPI = newBrokerAPI(brokerapi.WithOptionsEndpoint())
+		})
+
+		It("returns the supported methods", func() {
+			response := makeOptionsRequest()
+			Expect(response.Code).To(Equal(200))
+			Expect(response.Header().Get("Allow")).To(Equal("GET, PUT, PATCH, DELETE"))
+		})
+
+		It("lists the supported API versions", func() {
+			Expect(capabilities(makeOptionsRequest()).APIVersions).To(Equal([]string{"2.4"}))
+		})
+
+		It("marks which endpoints are implemented", func() {
+			response := capabilities(makeOptionsRequest())
+			Expect(endpoint(response, "provision").Implemented).To(BeTrue())
+			Expect(endpoint(response, "bind").Implemented).To(BeTrue())
+			Expect(endpoint(response, "update").Implemented).To(BeFalse())
+			Expect(endpoint(response, "last_operation").Implemented).To(BeFalse())
+			Expect(endpoint(response, "restore").Implemented).To(BeFalse())
+			Expect(endpoint(response, "tag").Implemented).To(BeFalse())
+			Expect(endpoint(response, "untag").Implemented).To(BeFalse())
+		})
+
+		It("reports that no operation is asynchronous", func() {
+			for _, endpoint := range capabilities(makeOptionsRequest()).Endpoints {
+				Expect(endpoint.Async).To(BeFalse())
+			}
+		})
+
+		Context("when restore is supported", func() {
+			BeforeEach(func() {
+				brokerAPI = newBrokerAPI(brokerapi.WithOptionsEndpoint(), brokerapi.WithRestoreSupport())
+			})
+
+			It("marks restore as implemented", func() {
+				Expect(endpoint(capabilities(makeOptionsRequest()), "restore").Implemented).To(BeTrue())
+			})
+		})
+
+		Context("when tagging is supported", func() {
+			BeforeEach(func() {
+				brokerAPI = newBrokerAPI(brokerapi.WithOptionsEndpoint(), brokerapi.WithTaggingSupport())
+			})
+
+			It("marks the tagging endpoints as implemented", func() {
+				response := capabilities(makeOptionsRequest())
+				Expect(endpoint(response, "tag").Path).To(Equal("/v2/service_instances/:instance_id/tags"))
+				Expect(endpoint(response, "tag").Implemented).To(BeTrue())
+				Expect(endpoint(response, "untag").Method).To(Equal("DELETE"))
+				Expect(endpoint(response, "untag").Path).To(Equal("/v2/service_instances/:instance_id/tags/:key"))
+				Expect(endpoint(response, "untag").Implemented).To(BeTrue())
+			})
+		})
+	})
+
+	Context("when the endpoint is not enabled", func() {
+		BeforeEach(func() {
+			brokerAPI = newBrokerAPI()
+		})
+
+		It("returns a 404", func() {
+			Expect(makeOptionsRequest().Code).To(Equal(404))
+		})
+	})
+})
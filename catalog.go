@@ -1,25 +1,61 @@
 package brokerapi
 
+import (
+	"errors"
+	"time"
+)
+
+var errPlanDoesNotExist = errors.New("plan does not exist")
+
 type Service struct {
-	ID          string          `json:"id"`
-	Name        string          `json:"name"`
-	Description string          `json:"description"`
-	Bindable    bool            `json:"bindable"`
-	Plans       []ServicePlan   `json:"plans"`
-	Metadata    ServiceMetadata `json:"metadata"`
-	Tags        []string        `json:"tags"`
+	ID              string                  `json:"id"`
+	Name            string                  `json:"name"`
+	Description     string                  `json:"description"`
+	Bindable        bool                    `json:"bindable"`
+	Plans           []ServicePlan           `json:"plans"`
+	Metadata        ServiceMetadata         `json:"metadata"`
+	Tags            []string                `json:"tags"`
+	Requires        []string                `json:"requires,omitempty"`
+	DashboardClient *ServiceDashboardClient `json:"dashboard_client,omitempty"`
+
+	MaximumInstances *int `json:"-"`
+}
+
+type ServiceDashboardClient struct {
+	ID          string `json:"id"`
+	Secret      string `json:"secret"`
+	RedirectURI string `json:"redirect_uri"`
+}
+
+func DashboardClientForPlan(planID string, planClients map[string]ServiceDashboardClient) *ServiceDashboardClient {
+	client, ok := planClients[planID]
+	if !ok {
+		return nil
+	}
+	return &client
 }
 
 type ServicePlan struct {
-	ID          string              `json:"id"`
-	Name        string              `json:"name"`
-	Description string              `json:"description"`
-	Metadata    ServicePlanMetadata `json:"metadata"`
+	ID             string              `json:"id"`
+	Name           string              `json:"name"`
+	Description    string              `json:"description"`
+	Metadata       ServicePlanMetadata `json:"metadata"`
+	Free           *bool               `json:"free,omitempty"`
+	Bindable       *bool               `json:"bindable,omitempty"`
+	ResourceQuotas map[string]int      `json:"-"`
+	PlanEnabled    *bool               `json:"-"`
+	DeprecatedAt   *time.Time          `json:"-"`
+	RemovalDate    *time.Time          `json:"-"`
+	ErrorMessages  map[string]string   `json:"-"`
+
+	MinimumInstances int `json:"-"`
 }
 
 type ServicePlanMetadata struct {
-	Bullets     []string `json:"bullets"`
-	DisplayName string   `json:"displayName"`
+	Bullets            []string `json:"bullets"`
+	DisplayName        string   `json:"displayName"`
+	Deprecated         bool     `json:"deprecated,omitempty"`
+	DeprecationMessage string   `json:"deprecation_message,omitempty"`
 }
 
 type ServiceMetadata struct {
@@ -39,3 +75,41 @@ type ServiceMetadataListing struct {
 type ServiceMetadataProvider struct {
 	Name string `json:"name"`
 }
+
+func findPlan(services []Service, planID string) (ServicePlan, bool) {
+	for _, service := range services {
+		for _, plan := range service.Plans {
+			if plan.ID == planID {
+				return plan, true
+			}
+		}
+	}
+	return ServicePlan{}, false
+}
+
+func findService(services []Service, serviceID string) (Service, bool) {
+	for _, service := range services {
+		if service.ID == serviceID {
+			return service, true
+		}
+	}
+	return Service{}, false
+}
+
+type requestCatalog struct {
+	serviceBroker ServiceBroker
+	services      []Service
+	fetched       bool
+}
+
+func newRequestCatalog(serviceBroker ServiceBroker) *requestCatalog {
+	return &requestCatalog{serviceBroker: serviceBroker}
+}
+
+func (catalog *requestCatalog) Services() []Service {
+	if !catalog.fetched {
+		catalog.services = catalog.serviceBroker.Services()
+		catalog.fetched = true
+	}
+	return catalog.services
+}
@@ -0,0 +1,84 @@
+package brokerapi
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const invalidCatalogFilterErrorKey = "invalid-catalog-filter"
+
+var catalogFilterPattern = regexp.MustCompile(`^(\w+)\(([\w.]+),"(.*)"\)$`)
+
+type catalogFilter func(service Service, plan ServicePlan) bool
+
+var catalogFilterOperators = map[string]func(actual, expected string) bool{
+	"eq": func(actual, expected string) bool { return actual == expected },
+	"ne": func(actual, expected string) bool { return actual != expected },
+	"co": strings.Contains,
+	"sw": strings.HasPrefix,
+}
+
+var catalogFilterFields = map[string]func(service Service, plan ServicePlan) string{
+	"service.id":   func(service Service, plan ServicePlan) string { return service.ID },
+	"service.name": func(service Service, plan ServicePlan) string { return service.Name },
+	"plan.id":      func(service Service, plan ServicePlan) string { return plan.ID },
+	"plan.name":    func(service Service, plan ServicePlan) string { return plan.Name },
+	"plan.free": func(service Service, plan ServicePlan) string {
+		return strconv.FormatBool(plan.Free == nil || *plan.Free)
+	},
+}
+
+type InvalidCatalogFilterError struct {
+	Filter string
+	Reason string
+}
+
+func (err InvalidCatalogFilterError) Error() string {
+	return fmt.Sprintf("invalid filter %s: %s", err.Filter, err.Reason)
+}
+
+func parseCatalogFilter(filter string) (catalogFilter, error) {
+	matches := catalogFilterPattern.FindStringSubmatch(filter)
+	if matches == nil {
+		return nil, InvalidCatalogFilterError{Filter: filter, Reason: `expected operator(field,"value")`}
+	}
+
+	operator, ok := catalogFilterOperators[matches[1]]
+	if !ok {
+		return nil, InvalidCatalogFilterError{Filter: filter, Reason: "unknown operator " + matches[1]}
+	}
+
+	field, ok := catalogFilterFields[matches[2]]
+	if !ok {
+		return nil, InvalidCatalogFilterError{Filter: filter, Reason: "unknown field " + matches[2]}
+	}
+
+	value := matches[3]
+	return func(service Service, plan ServicePlan) bool {
+		return operator(field(service, plan), value)
+	}, nil
+}
+
+func filterServices(services []Service, filter catalogFilter) []Service {
+	filtered := []Service{}
+
+	for _, service := range services {
+		plans := []ServicePlan{}
+		for _, plan := range service.Plans {
+			if filter(service, plan) {
+				plans = append(plans, plan)
+			}
+		}
+
+		if len(plans) == 0 {
+			continue
+		}
+
+		service.Plans = plans
+		filtered = append(filtered, service)
+	}
+
+	return filtered
+}
@@ -0,0 +1,118 @@
+package brokerapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/brokerapi"
+	"github.com/pivotal-cf/brokerapi/fakes"
+)
+
+var _ = Describe("Catalog filtering", func() {
+	var brokerAPI http.Handler
+	var credentials = brokerapi.BrokerCredentials{
+		Username: "username",
+		Password: "password",
+	}
+
+	paid := false
+	catalog := []brokerapi.Service{
+		{
+			ID:   "cassandra-id",
+			Name: "p-cassandra",
+			Plans: []brokerapi.ServicePlan{
+				{ID: "cassandra-small", Name: "small"},
+				{ID: "cassandra-large", Name: "large", Free: &paid},
+			},
+		},
+		{
+			ID:   "mysql-id",
+			Name: "p-mysql",
+			Plans: []brokerapi.ServicePlan{
+				{ID: "mysql-small", Name: "small"},
+			},
+		},
+	}
+
+	makeCatalogRequest := func(filter string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("GET", "/v2/catalog?filter="+url.QueryEscape(filter), nil)
+		request.SetBasicAuth(credentials.Username, credentials.Password)
+		brokerAPI.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	filteredPlanIDs := func(filter string) []string {
+		response := makeCatalogRequest(filter)
+		Expect(response.Code).To(Equal(200))
+
+		var catalogResponse brokerapi.CatalogResponse
+		err := json.Unmarshal(response.Body.Bytes(), &catalogResponse)
+		Expect(err).NotTo(HaveOccurred())
+
+		planIDs := []string{}
+		for _, service := range catalogResponse.Services {
+			for _, plan := range service.Plans {
+				planIDs = append(planIDs, plan.ID)
+			}
+		}
+		return planIDs
+	}
+
+	BeforeEach(func() {
+		fakeServiceBroker := &fakes.FakeServiceBroker{Catalog: catalog}
+		brokerAPI = brokerapi.New(fakeServiceBroker, lagertest.NewTestLogger("broker-api"), credentials, brokerapi.WithCatalogFiltering())
+	})
+
+	It("supports eq", func() {
+		Expect(filteredPlanIDs(`eq(service.id,"mysql-id")`)).To(Equal([]string{"mysql-small"}))
+	})
+
+	It("supports ne", func() {
+		Expect(filteredPlanIDs(`ne(plan.name,"small")`)).To(Equal([]string{"cassandra-large"}))
+	})
+
+	It("supports co", func() {
+		Expect(filteredPlanIDs(`co(service.name,"cass")`)).To(Equal([]string{"cassandra-small", "cassandra-large"}))
+	})
+
+	It("supports sw", func() {
+		Expect(filteredPlanIDs(`sw(plan.id,"mysql")`)).To(Equal([]string{"mysql-small"}))
+	})
+
+	It("treats plans as free unless marked otherwise", func() {
+		Expect(filteredPlanIDs(`eq(plan.free,"false")`)).To(Equal([]string{"cassandra-large"}))
+		Expect(filteredPlanIDs(`eq(plan.free,"true")`)).To(Equal([]string{"cassandra-small", "mysql-small"}))
+	})
+
+	It("compares values case-sensitively", func() {
+		Expect(filteredPlanIDs(`eq(service.name,"P-MYSQL")`)).To(BeEmpty())
+		Expect(filteredPlanIDs(`co(service.name,"MySQL")`)).To(BeEmpty())
+	})
+
+	It("returns a 400 for unknown fields", func() {
+		response := makeCatalogRequest(`eq(service.tags,"sql")`)
+		Expect(response.Code).To(Equal(400))
+		Expect(response.Body.String()).To(MatchJSON(`{"description":"invalid filter eq(service.tags,\"sql\"): unknown field service.tags"}`))
+	})
+
+	It("returns a 400 for unknown operators", func() {
+		Expect(makeCatalogRequest(`gt(plan.name,"small")`).Code).To(Equal(400))
+	})
+
+	It("returns a 400 for malformed filters", func() {
+		Expect(makeCatalogRequest(`service.id eq "mysql-id"`).Code).To(Equal(400))
+	})
+
+	It("ignores the filter unless enabled", func() {
+		brokerAPI = brokerapi.New(&fakes.FakeServiceBroker{Catalog: catalog}, lagertest.NewTestLogger("broker-api"), credentials)
+		Expect(filteredPlanIDs(`eq(service.id,"mysql-id")`)).To(HaveLen(3))
+	})
+})
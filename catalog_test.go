@@ -25,6 +25,46 @@ var _ = Describe("Catalog", func() {
 
 				Expect(service).To(MarshalToJSON(json))
 			})
+
+			It("includes the dashboard client when present", func() {
+				service := brokerapi.Service{
+					ID:          "ID-1",
+					Name:        "Cassandra",
+					Description: "A Cassandra Plan",
+					Bindable:    true,
+					Plans:       []brokerapi.ServicePlan{},
+					Metadata:    brokerapi.ServiceMetadata{},
+					Tags:        []string{},
+					DashboardClient: &brokerapi.ServiceDashboardClient{
+						ID:          "client-id",
+						Secret:      "client-secret",
+						RedirectURI: "https://dashboard.example.com",
+					},
+				}
+				json := `{"id":"ID-1","name":"Cassandra","description":"A Cassandra Plan","bindable":true,"plans":[],"metadata":{"displayName":"","longDescription":"","documentationUrl":"","supportUrl":"","listing":{"blurb":"","imageUrl":""},"provider":{"name":""}},"tags":[],"dashboard_client":{"id":"client-id","secret":"client-secret","redirect_uri":"https://dashboard.example.com"}}`
+
+				Expect(service).To(MarshalToJSON(json))
+			})
+		})
+	})
+
+	Describe("DashboardClientForPlan", func() {
+		planClients := map[string]brokerapi.ServiceDashboardClient{
+			"plan-small": {ID: "small-client", Secret: "small-secret", RedirectURI: "https://small.example.com"},
+			"plan-large": {ID: "large-client", Secret: "large-secret", RedirectURI: "https://large.example.com"},
+		}
+
+		It("returns the dashboard client of the given plan", func() {
+			client := brokerapi.DashboardClientForPlan("plan-large", planClients)
+			Expect(client).To(Equal(&brokerapi.ServiceDashboardClient{
+				ID:          "large-client",
+				Secret:      "large-secret",
+				RedirectURI: "https://large.example.com",
+			}))
+		})
+
+		It("returns nil when the plan has no dashboard client", func() {
+			Expect(brokerapi.DashboardClientForPlan("plan-unknown", planClients)).To(BeNil())
 		})
 	})
 
@@ -57,6 +97,18 @@ var _ = Describe("Catalog", func() {
 
 				Expect(metadata).To(MarshalToJSON(json))
 			})
+
+			It("includes the deprecation notice when the plan is deprecated", func() {
+				metadata := brokerapi.ServicePlanMetadata{
+					Bullets:            []string{},
+					DisplayName:        "Some display name",
+					Deprecated:         true,
+					DeprecationMessage: "use another plan",
+				}
+				json := `{"bullets":[],"displayName":"Some display name","deprecated":true,"deprecation_message":"use another plan"}`
+
+				Expect(metadata).To(MarshalToJSON(json))
+			})
 		})
 	})
 
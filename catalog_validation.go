@@ -0,0 +1,48 @@
+package brokerapi
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+var knownRequirements = map[string]bool{
+	"syslog_drain":     true,
+	"route_forwarding": true,
+	"volume_mount":     true,
+}
+
+type InvalidCatalogError []string
+
+func (err InvalidCatalogError) Error() string {
+	return "invalid catalog: " + strings.Join(err, "; ")
+}
+
+func ValidateCatalog(services []Service) error {
+	problems := InvalidCatalogError{}
+
+	for _, service := range services {
+		if !uuidPattern.MatchString(service.ID) {
+			problems = append(problems, fmt.Sprintf("service %q has invalid id %q", service.Name, service.ID))
+		}
+
+		for _, requirement := range service.Requires {
+			if !knownRequirements[requirement] {
+				problems = append(problems, fmt.Sprintf("service %q has unknown requires value %q", service.Name, requirement))
+			}
+		}
+
+		for _, plan := range service.Plans {
+			if !uuidPattern.MatchString(plan.ID) {
+				problems = append(problems, fmt.Sprintf("plan %q of service %q has invalid id %q", plan.Name, service.Name, plan.ID))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return problems
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package brokerapi_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/brokerapi"
+)
+
+var _ = Describe("Catalog validation", func() {
+	var services []brokerapi.Service
+
+	BeforeEach(func() {
+		services = []brokerapi.Service{
+			{
+				ID:   "0A789746-596F-4CEA-BFAC-A0795DA056E3",
+				Name: "p-cassandra",
+				Plans: []brokerapi.ServicePlan{
+					{
+						ID:   "ABE176EE-F69F-4A96-80CE-142595CC24E3",
+						Name: "default",
+					},
+				},
+			},
+		}
+	})
+
+	Context("when all service and plan IDs are UUIDs", func() {
+		It("returns no error", func() {
+			Expect(brokerapi.ValidateCatalog(services)).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("when a service ID is missing", func() {
+		BeforeEach(func() {
+			services[0].ID = ""
+		})
+
+		It("returns an error naming the service", func() {
+			err := brokerapi.ValidateCatalog(services)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(`service "p-cassandra" has invalid id ""`))
+		})
+	})
+
+	Context("when service and plan IDs are malformed", func() {
+		BeforeEach(func() {
+			services[0].ID = "not-a-uuid"
+			services[0].Plans[0].ID = "ABE176EE-F69F-4A96-80CE"
+			services[0].Plans = append(services[0].Plans, brokerapi.ServicePlan{
+				ID:   "plan-2",
+				Name: "large",
+			})
+		})
+
+		It("returns an error listing every invalid ID", func() {
+			err := brokerapi.ValidateCatalog(services)
+			Expect(err).To(Equal(brokerapi.InvalidCatalogError{
+				`service "p-cassandra" has invalid id "not-a-uuid"`,
+				`plan "default" of service "p-cassandra" has invalid id "ABE176EE-F69F-4A96-80CE"`,
+				`plan "large" of service "p-cassandra" has invalid id "plan-2"`,
+			}))
+		})
+	})
+
+	Context("when a service requires known permissions", func() {
+		BeforeEach(func() {
+			services[0].Requires = []string{"syslog_drain", "route_forwarding", "volume_mount"}
+		})
+
+		It("returns no error", func() {
+			Expect(brokerapi.ValidateCatalog(services)).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("when a service requires an unknown permission", func() {
+		BeforeEach(func() {
+			services[0].Requires = []string{"syslog_drain", "log_drain"}
+		})
+
+		It("returns an error naming the unknown value", func() {
+			err := brokerapi.ValidateCatalog(services)
+			Expect(err).To(Equal(brokerapi.InvalidCatalogError{
+				`service "p-cassandra" has unknown requires value "log_drain"`,
+			}))
+		})
+	})
+})
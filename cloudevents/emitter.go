@@ -0,0 +1,75 @@
+package cloudevents
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"code.google.com/p/go-uuid/uuid"
+	"github.com/pivotal-cf/brokerapi"
+)
+
+const SpecVersion = "1.0"
+const ContentType = "application/cloudevents+json"
+
+const eventTypePrefix = "org.osbapi."
+
+type Envelope struct {
+	SpecVersion     string                 `json:"specversion"`
+	Source          string                 `json:"source"`
+	Type            string                 `json:"type"`
+	ID              string                 `json:"id"`
+	Time            string                 `json:"time"`
+	DataContentType string                 `json:"datacontenttype"`
+	Data            brokerapi.WebhookEvent `json:"data"`
+}
+
+type cloudEventEmitter struct {
+	target string
+	client *http.Client
+}
+
+func NewCloudEventEmitter(target string) brokerapi.EventEmitter {
+	return &cloudEventEmitter{
+		target: target,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func NewEnvelope(event brokerapi.WebhookEvent) Envelope {
+	return Envelope{
+		SpecVersion:     SpecVersion,
+		Source:          "/v2/service_instances/" + event.InstanceID,
+		Type:            eventTypePrefix + event.EventType,
+		ID:              uuid.NewRandom().String(),
+		Time:            event.Timestamp,
+		DataContentType: "application/json",
+		Data:            event,
+	}
+}
+
+func (emitter *cloudEventEmitter) Emit(event brokerapi.WebhookEvent) error {
+	body, err := json.Marshal(NewEnvelope(event))
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest("POST", emitter.target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", ContentType)
+
+	response, err := emitter.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("cloudevents target responded with status %d", response.StatusCode)
+	}
+	return nil
+}
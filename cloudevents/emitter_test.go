@@ -0,0 +1,124 @@
+package cloudevents_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/brokerapi"
+	"github.com/pivotal-cf/brokerapi/cloudevents"
+	"github.com/pivotal-cf/brokerapi/fakes"
+)
+
+type receivedEvent struct {
+	contentType string
+	body        []byte
+}
+
+var _ = Describe("CloudEvent emitter", func() {
+	var server *httptest.Server
+	var received chan receivedEvent
+	var emitter brokerapi.EventEmitter
+
+	event := brokerapi.WebhookEvent{
+		EventType:        brokerapi.WebhookEventProvisioned,
+		InstanceID:       "instance-id",
+		ServiceID:        "service-id",
+		PlanID:           "plan-id",
+		OrganizationGUID: "organization-guid",
+		SpaceGUID:        "space-guid",
+		Timestamp:        "2030-01-01T00:00:00Z",
+	}
+
+	receive := func() (map[string]interface{}, receivedEvent) {
+		var request receivedEvent
+		Eventually(received, 5*time.Second).Should(Receive(&request))
+
+		var envelope map[string]interface{}
+		err := json.Unmarshal(request.body, &envelope)
+		Expect(err).NotTo(HaveOccurred())
+		return envelope, request
+	}
+
+	BeforeEach(func() {
+		received = make(chan receivedEvent, 10)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			body, _ := ioutil.ReadAll(req.Body)
+			received <- receivedEvent{
+				contentType: req.Header.Get("Content-Type"),
+				body:        body,
+			}
+		}))
+		emitter = cloudevents.NewCloudEventEmitter(server.URL)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("posts a structured CloudEvents 1.0 envelope", func() {
+		err := emitter.Emit(event)
+		Expect(err).NotTo(HaveOccurred())
+
+		envelope, request := receive()
+		Expect(request.contentType).To(Equal("application/cloudevents+json"))
+		Expect(envelope["specversion"]).To(Equal("1.0"))
+		Expect(envelope["type"]).To(Equal("org.osbapi.service_instance.provisioned"))
+		Expect(envelope["source"]).To(Equal("/v2/service_instances/instance-id"))
+		Expect(envelope["time"]).To(Equal("2030-01-01T00:00:00Z"))
+		Expect(envelope["datacontenttype"]).To(Equal("application/json"))
+		Expect(envelope["data"]).To(HaveKeyWithValue("instance_id", "instance-id"))
+		Expect(envelope["data"]).To(HaveKeyWithValue("plan_id", "plan-id"))
+	})
+
+	It("includes every attribute required by the spec", func() {
+		emitter.Emit(event)
+
+		envelope, _ := receive()
+		for _, attribute := range []string{"specversion", "id", "source", "type"} {
+			Expect(envelope[attribute]).NotTo(BeEmpty(), attribute)
+		}
+	})
+
+	It("gives each event a unique id", func() {
+		emitter.Emit(event)
+		emitter.Emit(event)
+
+		first, _ := receive()
+		second, _ := receive()
+		Expect(first["id"]).NotTo(Equal(second["id"]))
+	})
+
+	It("returns an error when the target rejects the event", func() {
+		server.Close()
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		emitter = cloudevents.NewCloudEventEmitter(server.URL)
+
+		Expect(emitter.Emit(event)).To(MatchError("cloudevents target responded with status 400"))
+	})
+
+	It("emits broker lifecycle events when registered with the API", func() {
+		credentials := brokerapi.BrokerCredentials{Username: "username", Password: "password"}
+		brokerAPI := brokerapi.New(&fakes.FakeServiceBroker{InstanceLimit: 3}, lagertest.NewTestLogger("broker-api"), credentials, brokerapi.WithEventEmitter(emitter))
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("PUT", "/v2/service_instances/instance-id", strings.NewReader(`{"plan_id":"plan-id"}`))
+		request.SetBasicAuth(credentials.Username, credentials.Password)
+		brokerAPI.ServeHTTP(recorder, request)
+		Expect(recorder.Code).To(Equal(201))
+
+		envelope, _ := receive()
+		Expect(envelope["type"]).To(Equal("org.osbapi.service_instance.provisioned"))
+		Expect(envelope["source"]).To(Equal("/v2/service_instances/instance-id"))
+	})
+})
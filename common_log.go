@@ -0,0 +1,85 @@
+package brokerapi
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const commonLogTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+type commonLogRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	size       int
+}
+
+func (recorder *commonLogRecorder) WriteHeader(statusCode int) {
+	recorder.statusCode = statusCode
+	recorder.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (recorder *commonLogRecorder) Write(data []byte) (int, error) {
+	size, err := recorder.ResponseWriter.Write(data)
+	recorder.size += size
+	return size, err
+}
+
+func (recorder *commonLogRecorder) Flush() {
+	if flusher, ok := recorder.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func wrapCommonLog(handler http.Handler, writer io.Writer) http.Handler {
+	var mutex sync.Mutex
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		received := time.Now()
+
+		recorder := &commonLogRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		handler.ServeHTTP(recorder, req)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		io.WriteString(writer, commonLogLine(req, recorder.statusCode, recorder.size, received))
+	})
+}
+
+func commonLogLine(req *http.Request, statusCode, size int, received time.Time) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+
+	user := "-"
+	if username, _, ok := req.BasicAuth(); ok && username != "" {
+		user = username
+	}
+
+	bytes := "-"
+	if size > 0 {
+		bytes = fmt.Sprint(size)
+	}
+
+	return fmt.Sprintf("%s - %s [%s] \"%s %s %s\" %d %s\n",
+		commonLogField(host),
+		user,
+		received.Format(commonLogTimeFormat),
+		req.Method,
+		req.URL.RequestURI(),
+		req.Proto,
+		statusCode,
+		bytes,
+	)
+}
+
+func commonLogField(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
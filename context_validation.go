@@ -0,0 +1,74 @@
+package brokerapi
+
+import "fmt"
+
+const invalidContextErrorKey = "invalid-context"
+
+type ContextValidator interface {
+	ValidateContext(context map[string]interface{}) error
+}
+
+type CFContextValidator struct{}
+
+func (CFContextValidator) ValidateContext(context map[string]interface{}) error {
+	if context["platform"] != "cloudfoundry" {
+		return nil
+	}
+	return requireContextFields(context, "organization_guid", "space_guid")
+}
+
+type KubernetesContextValidator struct {
+	AllowMissingNamespace bool
+	RequireClusterID      bool
+}
+
+func (validator KubernetesContextValidator) ValidateContext(context map[string]interface{}) error {
+	if context["platform"] != "kubernetes" {
+		return nil
+	}
+
+	fields := []string{}
+	if !validator.AllowMissingNamespace {
+		fields = append(fields, "namespace")
+	}
+	if validator.RequireClusterID {
+		fields = append(fields, "clusterid")
+	}
+	return requireContextFields(context, fields...)
+}
+
+type KubernetesContext struct {
+	ClusterID string
+	Namespace string
+}
+
+func ParseKubernetesContext(context map[string]interface{}) (KubernetesContext, bool) {
+	if context["platform"] != "kubernetes" {
+		return KubernetesContext{}, false
+	}
+
+	clusterID, _ := context["clusterid"].(string)
+	namespace, _ := context["namespace"].(string)
+	return KubernetesContext{
+		ClusterID: clusterID,
+		Namespace: namespace,
+	}, true
+}
+
+func requireContextFields(context map[string]interface{}, fields ...string) error {
+	for _, field := range fields {
+		if value, ok := context[field].(string); !ok || value == "" {
+			return fmt.Errorf("context for platform %v is missing %s", context["platform"], field)
+		}
+	}
+	return nil
+}
+
+func validateContext(context map[string]interface{}, validators []ContextValidator) error {
+	for _, validator := range validators {
+		if err := validator.ValidateContext(context); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package brokerapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/brokerapi"
+	"github.com/pivotal-cf/brokerapi/fakes"
+)
+
+var _ = Describe("Context validation", func() {
+	Describe("CFContextValidator", func() {
+		validator := brokerapi.CFContextValidator{}
+
+		It("accepts a complete Cloud Foundry context", func() {
+			err := validator.ValidateContext(map[string]interface{}{
+				"platform":          "cloudfoundry",
+				"organization_guid": "org-guid",
+				"space_guid":        "space-guid",
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("rejects a Cloud Foundry context without an organization", func() {
+			err := validator.ValidateContext(map[string]interface{}{
+				"platform":   "cloudfoundry",
+				"space_guid": "space-guid",
+			})
+			Expect(err).To(MatchError("context for platform cloudfoundry is missing organization_guid"))
+		})
+
+		It("rejects a Cloud Foundry context without a space", func() {
+			err := validator.ValidateContext(map[string]interface{}{
+				"platform":          "cloudfoundry",
+				"organization_guid": "org-guid",
+				"space_guid":        "",
+			})
+			Expect(err).To(MatchError("context for platform cloudfoundry is missing space_guid"))
+		})
+
+		It("ignores other platforms", func() {
+			err := validator.ValidateContext(map[string]interface{}{
+				"platform":  "kubernetes",
+				"namespace": "default",
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("KubernetesContextValidator", func() {
+		validator := brokerapi.KubernetesContextValidator{}
+
+		It("accepts a Kubernetes context with a namespace", func() {
+			err := validator.ValidateContext(map[string]interface{}{
+				"platform":  "kubernetes",
+				"namespace": "default",
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("rejects a Kubernetes context without a namespace", func() {
+			err := validator.ValidateContext(map[string]interface{}{
+				"platform": "kubernetes",
+			})
+			Expect(err).To(MatchError("context for platform kubernetes is missing namespace"))
+		})
+
+		It("ignores other platforms", func() {
+			err := validator.ValidateContext(map[string]interface{}{
+				"platform":          "cloudfoundry",
+				"organization_guid": "org-guid",
+				"space_guid":        "space-guid",
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("KubernetesContextValidator requiring a cluster ID", func() {
+		validator := brokerapi.KubernetesContextValidator{RequireClusterID: true}
+
+		It("accepts a Kubernetes context with a namespace and cluster ID", func() {
+			err := validator.ValidateContext(map[string]interface{}{
+				"platform":  "kubernetes",
+				"clusterid": "cluster-id",
+				"namespace": "default",
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("rejects a Kubernetes context without a cluster ID", func() {
+			err := validator.ValidateContext(map[string]interface{}{
+				"platform":  "kubernetes",
+				"namespace": "default",
+			})
+			Expect(err).To(MatchError("context for platform kubernetes is missing clusterid"))
+		})
+
+		It("still requires a namespace", func() {
+			err := validator.ValidateContext(map[string]interface{}{
+				"platform":  "kubernetes",
+				"clusterid": "cluster-id",
+			})
+			Expect(err).To(MatchError("context for platform kubernetes is missing namespace"))
+		})
+	})
+
+	Describe("KubernetesContextValidator allowing a missing namespace", func() {
+		validator := brokerapi.KubernetesContextValidator{AllowMissingNamespace: true}
+
+		It("accepts a Kubernetes context without a namespace", func() {
+			err := validator.ValidateContext(map[string]interface{}{
+				"platform": "kubernetes",
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("still requires a cluster ID when configured", func() {
+			validator := brokerapi.KubernetesContextValidator{AllowMissingNamespace: true, RequireClusterID: true}
+			err := validator.ValidateContext(map[string]interface{}{
+				"platform": "kubernetes",
+			})
+			Expect(err).To(MatchError("context for platform kubernetes is missing clusterid"))
+		})
+	})
+
+	Describe("ParseKubernetesContext", func() {
+		It("returns the cluster ID and namespace of a Kubernetes context", func() {
+			context, ok := brokerapi.ParseKubernetesContext(map[string]interface{}{
+				"platform":  "kubernetes",
+				"clusterid": "cluster-id",
+				"namespace": "default",
+			})
+			Expect(ok).To(BeTrue())
+			Expect(context).To(Equal(brokerapi.KubernetesContext{
+				ClusterID: "cluster-id",
+				Namespace: "default",
+			}))
+		})
+
+		It("returns empty fields when they are missing", func() {
+			context, ok := brokerapi.ParseKubernetesContext(map[string]interface{}{
+				"platform": "kubernetes",
+			})
+			Expect(ok).To(BeTrue())
+			Expect(context).To(Equal(brokerapi.KubernetesContext{}))
+		})
+
+		It("does not parse other platforms", func() {
+			_, ok := brokerapi.ParseKubernetesContext(map[string]interface{}{
+				"platform":          "cloudfoundry",
+				"organization_guid": "org-guid",
+			})
+			Expect(ok).To(BeFalse())
+		})
+
+		It("does not parse a missing context", func() {
+			_, ok := brokerapi.ParseKubernetesContext(nil)
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Describe("when wired into the API", func() {
+		var fakeServiceBroker *fakes.FakeServiceBroker
+		var brokerAPI http.Handler
+		var credentials = brokerapi.BrokerCredentials{
+			Username: "username",
+			Password: "password",
+		}
+
+		makeRequest := func(method, path, body string) *httptest.ResponseRecorder {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest(method, path, strings.NewReader(body))
+			request.SetBasicAuth(credentials.Username, credentials.Password)
+			brokerAPI.ServeHTTP(recorder, request)
+			return recorder
+		}
+
+		BeforeEach(func() {
+			fakeServiceBroker = &fakes.FakeServiceBroker{
+				InstanceLimit: 3,
+			}
+			brokerAPI = brokerapi.New(fakeServiceBroker, lagertest.NewTestLogger("broker-api"), credentials,
+				brokerapi.WithContextValidators(brokerapi.CFContextValidator{}, brokerapi.KubernetesContextValidator{}))
+		})
+
+		It("rejects provision requests with an invalid context", func() {
+			response := makeRequest("PUT", "/v2/service_instances/"+uniqueInstanceID(), `{"plan_id":"plan-id","context":{"platform":"kubernetes"}}`)
+			Expect(response.Code).To(Equal(400))
+			Expect(response.Body.String()).To(MatchJSON(`{"description":"context for platform kubernetes is missing namespace"}`))
+			Expect(fakeServiceBroker.ProvisionedInstanceIDs).To(BeEmpty())
+		})
+
+		It("passes a valid context to the broker", func() {
+			response := makeRequest("PUT", "/v2/service_instances/"+uniqueInstanceID(), `{"plan_id":"plan-id","context":{"platform":"kubernetes","namespace":"default"}}`)
+			Expect(response.Code).To(Equal(201))
+			Expect(fakeServiceBroker.ServiceDetails.Context).To(Equal(map[string]interface{}{
+				"platform":  "kubernetes",
+				"namespace": "default",
+			}))
+		})
+
+		It("accepts provision requests without a context", func() {
+			response := makeRequest("PUT", "/v2/service_instances/"+uniqueInstanceID(), `{"plan_id":"plan-id"}`)
+			Expect(response.Code).To(Equal(201))
+		})
+
+		It("rejects bind requests with an invalid context", func() {
+			response := makeRequest("PUT", "/v2/service_instances/"+uniqueInstanceID()+"/service_bindings/"+uniqueBindingID(), `{"context":{"platform":"cloudfoundry","organization_guid":"org-guid"}}`)
+			Expect(response.Code).To(Equal(400))
+			Expect(fakeServiceBroker.BoundBindingIDs).To(BeEmpty())
+		})
+	})
+})
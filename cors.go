@@ -0,0 +1,67 @@
+package brokerapi
+
+import (
+	"net/http"
+	"strings"
+)
+
+var corsPaths = []string{"/v2/catalog"}
+
+type CORSConfig struct {
+	AllowedOrigins []string
+	AllowedMethods []string
+}
+
+func wrapCORS(handler http.Handler, config CORSConfig) http.Handler {
+	allowedMethods := config.AllowedMethods
+	if len(allowedMethods) == 0 {
+		allowedMethods = []string{"GET"}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		origin := req.Header.Get("Origin")
+		if origin == "" || !corsPath(req.URL.Path) {
+			handler.ServeHTTP(w, req)
+			return
+		}
+
+		switch {
+		case originListed(origin, config.AllowedOrigins):
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+			w.Header().Add("Vary", "Origin")
+		case originListed("*", config.AllowedOrigins):
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		default:
+			handler.ServeHTTP(w, req)
+			return
+		}
+
+		if req.Method == "OPTIONS" && req.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", strings.Join(allowedMethods, ", "))
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, X-Broker-Api-Version")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		handler.ServeHTTP(w, req)
+	})
+}
+
+func corsPath(path string) bool {
+	for _, corsPath := range corsPaths {
+		if path == corsPath {
+			return true
+		}
+	}
+	return false
+}
+
+func originListed(origin string, allowedOrigins []string) bool {
+	for _, allowedOrigin := range allowedOrigins {
+		if allowedOrigin == origin {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package brokerapi
+
+import (
+	"fmt"
+	"net/url"
+)
+
+type DatabaseCredentials struct {
+	URI      string `json:"uri"`
+	JDBCURL  string `json:"jdbcUrl"`
+	Hostname string `json:"hostname"`
+	Port     int    `json:"port"`
+	Name     string `json:"name"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+func NewMySQLCredentials(hostname string, port int, name, username, password string) DatabaseCredentials {
+	return newDatabaseCredentials("mysql", "mysql", hostname, port, name, username, password)
+}
+
+func NewPostgresCredentials(hostname string, port int, name, username, password string) DatabaseCredentials {
+	return newDatabaseCredentials("postgres", "postgresql", hostname, port, name, username, password)
+}
+
+func newDatabaseCredentials(scheme, jdbcScheme, hostname string, port int, name, username, password string) DatabaseCredentials {
+	host := fmt.Sprintf("%s:%d", hostname, port)
+
+	uri := url.URL{
+		Scheme: scheme,
+		User:   url.UserPassword(username, password),
+		Host:   host,
+		Path:   "/" + name,
+	}
+
+	jdbcURL := url.URL{
+		Scheme: jdbcScheme,
+		Host:   host,
+		Path:   "/" + name,
+		RawQuery: url.Values{
+			"user":     []string{username},
+			"password": []string{password},
+		}.Encode(),
+	}
+
+	return DatabaseCredentials{
+		URI:      uri.String(),
+		JDBCURL:  "jdbc:" + jdbcURL.String(),
+		Hostname: hostname,
+		Port:     port,
+		Name:     name,
+		Username: username,
+		Password: password,
+	}
+}
+
+type TLSCredentials struct {
+	TLSEnabled bool   `json:"tls_enabled"`
+	CACert     string `json:"ca_cert,omitempty"`
+	ClientCert string `json:"client_cert,omitempty"`
+	ClientKey  string `json:"client_key,omitempty"`
+}
+
+func NewTLSCredentials(caCert, clientCert, clientKey string) TLSCredentials {
+	return TLSCredentials{
+		TLSEnabled: true,
+		CACert:     caCert,
+		ClientCert: clientCert,
+		ClientKey:  clientKey,
+	}
+}
@@ -0,0 +1,29 @@
+package brokerapi
+
+import (
+	"errors"
+	"time"
+)
+
+const planRemovedErrorKey = "plan-removed"
+const planPastDeprecationLogKey = "plan-past-deprecation"
+
+var errPlanRemoved = errors.New("this plan has been removed")
+
+type ClockProvider interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func planRemoved(plan ServicePlan, now time.Time) bool {
+	return plan.RemovalDate != nil && now.After(*plan.RemovalDate)
+}
+
+func planPastDeprecation(plan ServicePlan, now time.Time) bool {
+	return plan.DeprecatedAt != nil && now.After(*plan.DeprecatedAt)
+}
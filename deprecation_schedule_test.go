@@ -0,0 +1,126 @@
+package brokerapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/brokerapi"
+	"github.com/pivotal-cf/brokerapi/fakes"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (clock *fakeClock) Now() time.Time {
+	return clock.now
+}
+
+var _ = Describe("Plan deprecation schedule", func() {
+	const planID = "ABE176EE-F69F-4A96-80CE-142595CC24E3"
+
+	var fakeServiceBroker *fakes.FakeServiceBroker
+	var brokerLogger *lagertest.TestLogger
+	var clock *fakeClock
+	var brokerAPI http.Handler
+	var credentials = brokerapi.BrokerCredentials{
+		Username: "username",
+		Password: "password",
+	}
+
+	deprecatedAt := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+	removalDate := time.Date(2030, time.July, 1, 0, 0, 0, 0, time.UTC)
+
+	provision := func() *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("PUT", "/v2/service_instances/"+uniqueInstanceID(), strings.NewReader(`{"plan_id":"`+planID+`"}`))
+		request.SetBasicAuth(credentials.Username, credentials.Password)
+		brokerAPI.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	deprecationLogs := func() []lager.LogFormat {
+		logs := []lager.LogFormat{}
+		for _, log := range brokerLogger.Logs() {
+			if log.Message == "broker-api.provision.plan-past-deprecation" {
+				logs = append(logs, log)
+			}
+		}
+		return logs
+	}
+
+	BeforeEach(func() {
+		fakeServiceBroker = &fakes.FakeServiceBroker{
+			InstanceLimit: 3,
+		}
+		services := fakeServiceBroker.Services()
+		services[0].Plans[0].DeprecatedAt = &deprecatedAt
+		services[0].Plans[0].RemovalDate = &removalDate
+		fakeServiceBroker.Catalog = services
+
+		brokerLogger = lagertest.NewTestLogger("broker-api")
+		clock = &fakeClock{}
+		brokerAPI = brokerapi.New(fakeServiceBroker, brokerLogger, credentials, brokerapi.WithDeprecationSchedule(), brokerapi.WithClock(clock))
+	})
+
+	Context("before the plan is deprecated", func() {
+		BeforeEach(func() {
+			clock.now = deprecatedAt.Add(-time.Hour)
+		})
+
+		It("provisions without a warning", func() {
+			Expect(provision().Code).To(Equal(201))
+			Expect(deprecationLogs()).To(BeEmpty())
+		})
+	})
+
+	Context("after the plan is deprecated but before it is removed", func() {
+		BeforeEach(func() {
+			clock.now = deprecatedAt.Add(time.Hour)
+		})
+
+		It("provisions and logs a warning", func() {
+			Expect(provision().Code).To(Equal(201))
+
+			logs := deprecationLogs()
+			Expect(logs).To(HaveLen(1))
+			Expect(logs[0].LogLevel).To(Equal(lager.INFO))
+			Expect(logs[0].Data["plan-id"]).To(Equal(planID))
+		})
+	})
+
+	Context("after the plan is removed", func() {
+		BeforeEach(func() {
+			clock.now = removalDate.Add(time.Hour)
+		})
+
+		It("returns a 422", func() {
+			response := provision()
+			Expect(response.Code).To(Equal(422))
+			Expect(response.Body.String()).To(MatchJSON(`{"description":"this plan has been removed"}`))
+			Expect(fakeServiceBroker.ProvisionedInstanceIDs).To(BeEmpty())
+		})
+	})
+
+	It("ignores the schedule unless it is enabled", func() {
+		brokerAPI = brokerapi.New(fakeServiceBroker, brokerLogger, credentials, brokerapi.WithClock(clock))
+		clock.now = removalDate.Add(time.Hour)
+		Expect(provision().Code).To(Equal(201))
+		Expect(deprecationLogs()).To(BeEmpty())
+	})
+
+	It("ignores plans without a schedule", func() {
+		fakeServiceBroker.Catalog = nil
+		clock.now = removalDate.Add(time.Hour)
+		Expect(provision().Code).To(Equal(201))
+		Expect(deprecationLogs()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,9 @@
+package brokerapi
+
+const (
+	ErrorCodeAsyncRequired           = "AsyncRequired"
+	ErrorCodeConcurrencyError        = "ConcurrencyError"
+	ErrorCodeRequiresApp             = "RequiresApp"
+	ErrorCodeMaintenanceInfoConflict = "MaintenanceInfoConflict"
+	ErrorCodeQuotaExceeded           = "QuotaExceeded"
+)
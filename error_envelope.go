@@ -0,0 +1,86 @@
+package brokerapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+type ErrorEnvelope func(statusCode int, code, description string) interface{}
+
+type NestedError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type NestedErrorResponse struct {
+	Error NestedError `json:"error"`
+}
+
+func NestedErrorEnvelope(statusCode int, code, description string) interface{} {
+	if code == "" {
+		code = strings.Replace(strings.ToLower(http.StatusText(statusCode)), " ", "_", -1)
+	}
+
+	return NestedErrorResponse{
+		Error: NestedError{
+			Code:    code,
+			Message: description,
+		},
+	}
+}
+
+type errorEnvelopeWriter struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (writer *errorEnvelopeWriter) WriteHeader(statusCode int) {
+	writer.statusCode = statusCode
+	if !writer.isError() {
+		writer.ResponseWriter.WriteHeader(statusCode)
+	}
+}
+
+func (writer *errorEnvelopeWriter) Write(data []byte) (int, error) {
+	if writer.isError() {
+		return writer.body.Write(data)
+	}
+	return writer.ResponseWriter.Write(data)
+}
+
+func (writer *errorEnvelopeWriter) Flush() {
+	if writer.isError() {
+		return
+	}
+	if flusher, ok := writer.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (writer *errorEnvelopeWriter) isError() bool {
+	return writer.statusCode >= 400
+}
+
+func wrapErrorEnvelope(handler http.Handler, envelope ErrorEnvelope) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		writer := &errorEnvelopeWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		handler.ServeHTTP(writer, req)
+
+		if !writer.isError() {
+			return
+		}
+
+		code := ""
+		description := strings.TrimSpace(writer.body.String())
+		var errorResponse ErrorResponse
+		if err := json.Unmarshal(writer.body.Bytes(), &errorResponse); err == nil {
+			code = errorResponse.Error
+			description = errorResponse.Description
+		}
+
+		respond(w, writer.statusCode, envelope(writer.statusCode, code, description))
+	})
+}
@@ -0,0 +1,132 @@
+package brokerapi_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/brokerapi"
+	"github.com/pivotal-cf/brokerapi/fakes"
+)
+
+var _ = Describe("Error envelope", func() {
+	var fakeServiceBroker *fakes.FakeServiceBroker
+	var brokerAPI http.Handler
+	var credentials = brokerapi.BrokerCredentials{
+		Username: "username",
+		Password: "password",
+	}
+
+	provision := func(body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("PUT", "/v2/service_instances/"+uniqueInstanceID(), strings.NewReader(body))
+		request.SetBasicAuth(credentials.Username, credentials.Password)
+		brokerAPI.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	BeforeEach(func() {
+		fakeServiceBroker = &fakes.FakeServiceBroker{
+			InstanceLimit: 3,
+		}
+	})
+
+	It("uses the spec envelope by default", func() {
+		brokerAPI = brokerapi.New(fakeServiceBroker, lagertest.NewTestLogger("broker-api"), credentials)
+		fakeServiceBroker.ProvisionError = errors.New("broker failed")
+
+		response := provision(`{"plan_id":"plan-id"}`)
+		Expect(response.Code).To(Equal(500))
+		Expect(response.Body.String()).To(MatchJSON(`{"description":"broker failed"}`))
+	})
+
+	Context("when a custom envelope is configured", func() {
+		BeforeEach(func() {
+			brokerAPI = brokerapi.New(fakeServiceBroker, lagertest.NewTestLogger("broker-api"), credentials, brokerapi.WithErrorEnvelope(brokerapi.NestedErrorEnvelope))
+		})
+
+		It("wraps error descriptions in the custom envelope", func() {
+			fakeServiceBroker.ProvisionError = errors.New("broker failed")
+
+			response := provision(`{"plan_id":"plan-id"}`)
+			Expect(response.Code).To(Equal(500))
+			Expect(response.Header().Get("Content-Type")).To(Equal("application/json"))
+			Expect(response.Body.String()).To(MatchJSON(`{"error":{"code":"internal_server_error","message":"broker failed"}}`))
+		})
+
+		It("wraps errors without a description", func() {
+			fakeServiceBroker.ProvisionError = brokerapi.ErrInstanceAlreadyExists
+
+			response := provision(`{"plan_id":"plan-id"}`)
+			Expect(response.Code).To(Equal(409))
+			Expect(response.Body.String()).To(MatchJSON(`{"error":{"code":"conflict","message":""}}`))
+		})
+
+		It("uses the Service Broker API error code when there is one", func() {
+			fakeServiceBroker.ProvisionError = brokerapi.ErrConcurrentOperation
+
+			response := provision(`{"plan_id":"plan-id"}`)
+			Expect(response.Code).To(Equal(422))
+			Expect(response.Body.String()).To(MatchJSON(`{"error":{"code":"ConcurrencyError","message":"another operation for this service instance is in progress"}}`))
+		})
+
+		It("wraps authentication failures", func() {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("GET", "/v2/catalog", nil)
+			brokerAPI.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(401))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+			Expect(recorder.Body.String()).To(MatchJSON(`{"error":{"code":"unauthorized","message":"Not Authorized"}}`))
+		})
+
+		It("wraps unknown routes", func() {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("GET", "/v2/unknown", nil)
+			request.SetBasicAuth(credentials.Username, credentials.Password)
+			brokerAPI.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(404))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+			Expect(recorder.Body.String()).To(MatchJSON(`{"error":{"code":"not_found","message":"404 page not found"}}`))
+		})
+
+		It("wraps errors from the header audit", func() {
+			brokerAPI = brokerapi.New(fakeServiceBroker, lagertest.NewTestLogger("broker-api"), credentials, brokerapi.WithErrorEnvelope(brokerapi.NestedErrorEnvelope), brokerapi.WithHeaderAudit(64))
+
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("GET", "/v2/catalog", nil)
+			request.SetBasicAuth(credentials.Username, credentials.Password)
+			request.Header.Set("X-Large", strings.Repeat("a", 128))
+			brokerAPI.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(431))
+			Expect(recorder.Body.String()).To(ContainSubstring(`"error":{"code":`))
+		})
+
+		It("passes the error code to custom envelopes", func() {
+			var envelopeCode string
+			brokerAPI = brokerapi.New(fakeServiceBroker, lagertest.NewTestLogger("broker-api"), credentials, brokerapi.WithErrorEnvelope(func(statusCode int, code, description string) interface{} {
+				envelopeCode = code
+				return map[string]string{"reason": description}
+			}))
+			fakeServiceBroker.ProvisionError = brokerapi.ErrConcurrentOperation
+
+			response := provision(`{"plan_id":"plan-id"}`)
+			Expect(response.Code).To(Equal(422))
+			Expect(envelopeCode).To(Equal(brokerapi.ErrorCodeConcurrencyError))
+		})
+
+		It("leaves successful responses untouched", func() {
+			response := provision(`{"plan_id":"plan-id"}`)
+			Expect(response.Code).To(Equal(201))
+			Expect(response.Body.String()).To(MatchJSON(`{}`))
+		})
+	})
+})
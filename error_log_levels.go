@@ -0,0 +1,38 @@
+package brokerapi
+
+import (
+	"reflect"
+
+	"github.com/pivotal-golang/lager"
+)
+
+var DefaultErrorLogLevels = map[error]lager.LogLevel{
+	ErrInstanceAlreadyExists: lager.INFO,
+	ErrBindingAlreadyExists:  lager.INFO,
+}
+
+func logBrokerError(logger lager.Logger, levels map[error]lager.LogLevel, action string, err error, data ...lager.Data) {
+	if len(levels) == 0 || !reflect.TypeOf(err).Comparable() {
+		logger.Error(action, err, data...)
+		return
+	}
+
+	level, ok := levels[err]
+	if !ok || level >= lager.ERROR {
+		logger.Error(action, err, data...)
+		return
+	}
+
+	logData := lager.Data{"error": err.Error()}
+	for _, d := range data {
+		for key, value := range d {
+			logData[key] = value
+		}
+	}
+
+	if level == lager.DEBUG {
+		logger.Debug(action, logData)
+	} else {
+		logger.Info(action, logData)
+	}
+}
@@ -0,0 +1,112 @@
+package brokerapi_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/brokerapi"
+	"github.com/pivotal-cf/brokerapi/fakes"
+)
+
+var _ = Describe("Error log levels", func() {
+	var fakeServiceBroker *fakes.FakeServiceBroker
+	var brokerLogger *lagertest.TestLogger
+	var brokerAPI http.Handler
+	var credentials = brokerapi.BrokerCredentials{
+		Username: "username",
+		Password: "password",
+	}
+
+	makeProvisionRequest := func() *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("PUT", "/v2/service_instances/instance-id", strings.NewReader(`{"service_id":"service-id","plan_id":"plan-id"}`))
+		request.SetBasicAuth(credentials.Username, credentials.Password)
+		brokerAPI.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	logWithMessage := func(message string) lager.LogFormat {
+		for _, log := range brokerLogger.Logs() {
+			if log.Message == message {
+				return log
+			}
+		}
+		Fail("no log line with message " + message)
+		return lager.LogFormat{}
+	}
+
+	BeforeEach(func() {
+		fakeServiceBroker = &fakes.FakeServiceBroker{
+			InstanceLimit:          3,
+			ProvisionedInstanceIDs: []string{"instance-id"},
+		}
+		brokerLogger = lagertest.NewTestLogger("broker-api")
+	})
+
+	Context("without a mapping", func() {
+		BeforeEach(func() {
+			brokerAPI = brokerapi.New(fakeServiceBroker, brokerLogger, credentials)
+		})
+
+		It("logs routine conflicts at error level", func() {
+			Expect(makeProvisionRequest().Code).To(Equal(409))
+			Expect(logWithMessage("broker-api.provision.instance-already-exists").LogLevel).To(Equal(lager.ERROR))
+		})
+
+		It("returns a 500 for a broker error of a non-comparable type", func() {
+			fakeServiceBroker.ProvisionError = brokerapi.InvalidCatalogError{"boom"}
+
+			Expect(makeProvisionRequest().Code).To(Equal(500))
+			Expect(logWithMessage("broker-api.provision.unknown-error").LogLevel).To(Equal(lager.ERROR))
+		})
+	})
+
+	Context("with the default mapping", func() {
+		BeforeEach(func() {
+			brokerAPI = brokerapi.New(fakeServiceBroker, brokerLogger, credentials, brokerapi.WithErrorLogLevels(brokerapi.DefaultErrorLogLevels))
+		})
+
+		It("logs a 409 at info level", func() {
+			Expect(makeProvisionRequest().Code).To(Equal(409))
+
+			log := logWithMessage("broker-api.provision.instance-already-exists")
+			Expect(log.LogLevel).To(Equal(lager.INFO))
+			Expect(log.Data["error"]).To(Equal("instance already exists"))
+		})
+
+		It("logs a 500 at error level", func() {
+			fakeServiceBroker.ProvisionError = errors.New("boom")
+
+			Expect(makeProvisionRequest().Code).To(Equal(500))
+			Expect(logWithMessage("broker-api.provision.unknown-error").LogLevel).To(Equal(lager.ERROR))
+		})
+
+		It("returns a 500 for a broker error of a non-comparable type", func() {
+			fakeServiceBroker.ProvisionError = brokerapi.InvalidCatalogError{"boom"}
+
+			Expect(makeProvisionRequest().Code).To(Equal(500))
+			Expect(logWithMessage("broker-api.provision.unknown-error").LogLevel).To(Equal(lager.ERROR))
+		})
+	})
+
+	Context("with a debug mapping", func() {
+		BeforeEach(func() {
+			brokerAPI = brokerapi.New(fakeServiceBroker, brokerLogger, credentials, brokerapi.WithErrorLogLevels(map[error]lager.LogLevel{
+				brokerapi.ErrInstanceAlreadyExists: lager.DEBUG,
+			}))
+		})
+
+		It("logs the error at debug level", func() {
+			makeProvisionRequest()
+			Expect(logWithMessage("broker-api.provision.instance-already-exists").LogLevel).To(Equal(lager.DEBUG))
+		})
+	})
+})
@@ -0,0 +1,144 @@
+package brokerapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pivotal-golang/lager"
+)
+
+const eventsLogKey = "events"
+const appendEventErrorKey = "append-event-failed"
+
+const (
+	OperationSucceeded = "succeeded"
+	OperationFailed    = "failed"
+)
+
+type EventLog interface {
+	Append(event OperationEvent) error
+	Events(instanceID string) ([]OperationEvent, error)
+}
+
+type OperationEvent struct {
+	InstanceID string            `json:"instance_id"`
+	Timestamp  time.Time         `json:"timestamp"`
+	Operation  string            `json:"operation"`
+	Actor      string            `json:"actor"`
+	State      string            `json:"state"`
+	Details    map[string]string `json:"details"`
+}
+
+type EventsResponse struct {
+	Events []OperationEvent `json:"events"`
+}
+
+type memoryEventLog struct {
+	mutex  sync.Mutex
+	events map[string][]OperationEvent
+}
+
+func NewMemoryEventLog() EventLog {
+	return &memoryEventLog{
+		events: map[string][]OperationEvent{},
+	}
+}
+
+func (log *memoryEventLog) Append(event OperationEvent) error {
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+
+	log.events[event.InstanceID] = append(log.events[event.InstanceID], event)
+	return nil
+}
+
+func (log *memoryEventLog) Events(instanceID string) ([]OperationEvent, error) {
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+
+	return append([]OperationEvent{}, log.events[instanceID]...), nil
+}
+
+type eventRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (recorder *eventRecorder) WriteHeader(statusCode int) {
+	recorder.statusCode = statusCode
+	recorder.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (recorder *eventRecorder) Write(data []byte) (int, error) {
+	recorder.body.Write(data)
+	return recorder.ResponseWriter.Write(data)
+}
+
+func recordEvents(eventLog EventLog, operation string, router httpRouter, logger lager.Logger, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		vars := router.Vars(req)
+
+		recorder := &eventRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		handler(recorder, req)
+
+		event := OperationEvent{
+			InstanceID: vars["instance_id"],
+			Timestamp:  time.Now().UTC(),
+			Operation:  operation,
+			Actor:      req.Header.Get(originatingIdentityHeader),
+			State:      OperationSucceeded,
+			Details: map[string]string{
+				"status_code": strconv.Itoa(recorder.statusCode),
+			},
+		}
+
+		if bindingID := vars["binding_id"]; bindingID != "" {
+			event.Details["binding_id"] = bindingID
+		}
+
+		if recorder.statusCode < 200 || recorder.statusCode > 299 {
+			event.State = OperationFailed
+
+			var errorResponse ErrorResponse
+			if json.Unmarshal(recorder.body.Bytes(), &errorResponse) == nil && errorResponse.Description != "" {
+				event.Details["description"] = errorResponse.Description
+			}
+		}
+
+		if err := eventLog.Append(event); err != nil {
+			logger.Error(appendEventErrorKey, err, lager.Data{
+				instanceIDLogKey: event.InstanceID,
+			})
+		}
+	}
+}
+
+func events(eventLog EventLog, router httpRouter, logger lager.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		instanceID := router.Vars(req)["instance_id"]
+
+		logger := logger.Session(eventsLogKey, lager.Data{
+			instanceIDLogKey: instanceID,
+		})
+
+		events, err := eventLog.Events(instanceID)
+		if err != nil {
+			logger.Error(unknownErrorKey, err)
+			respond(w, http.StatusInternalServerError, ErrorResponse{
+				Description: err.Error(),
+			})
+			return
+		}
+
+		if events == nil {
+			events = []OperationEvent{}
+		}
+
+		respond(w, http.StatusOK, EventsResponse{Events: events})
+	}
+}
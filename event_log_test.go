@@ -0,0 +1,113 @@
+package brokerapi_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/brokerapi"
+	"github.com/pivotal-cf/brokerapi/fakes"
+)
+
+var _ = Describe("Event log", func() {
+	const identity = "cloudfoundry eyJ1c2VyX2lkIjoiYWRtaW4ifQ=="
+
+	var fakeServiceBroker *fakes.FakeServiceBroker
+	var eventLog brokerapi.EventLog
+	var brokerAPI http.Handler
+	var instanceID string
+	var credentials = brokerapi.BrokerCredentials{
+		Username: "username",
+		Password: "password",
+	}
+
+	makeRequest := func(method, path, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(method, path, strings.NewReader(body))
+		request.SetBasicAuth(credentials.Username, credentials.Password)
+		request.Header.Set("X-Broker-API-Originating-Identity", identity)
+		brokerAPI.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	provision := func() *httptest.ResponseRecorder {
+		return makeRequest("PUT", "/v2/service_instances/"+instanceID, `{"service_id":"service-id","plan_id":"plan-id"}`)
+	}
+
+	BeforeEach(func() {
+		instanceID = uniqueInstanceID()
+		fakeServiceBroker = &fakes.FakeServiceBroker{
+			InstanceLimit: 3,
+		}
+		eventLog = brokerapi.NewMemoryEventLog()
+		brokerAPI = brokerapi.New(fakeServiceBroker, lagertest.NewTestLogger("broker-api"), credentials, brokerapi.WithEventLog(eventLog))
+	})
+
+	It("appends successful operations", func() {
+		provision()
+		makeRequest("PUT", "/v2/service_instances/"+instanceID+"/service_bindings/binding-id", "")
+
+		events, err := eventLog.Events(instanceID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(2))
+
+		Expect(events[0].InstanceID).To(Equal(instanceID))
+		Expect(events[0].Operation).To(Equal("provision"))
+		Expect(events[0].Actor).To(Equal(identity))
+		Expect(events[0].State).To(Equal(brokerapi.OperationSucceeded))
+		Expect(events[0].Details).To(Equal(map[string]string{"status_code": "201"}))
+		Expect(events[0].Timestamp).To(BeTemporally("~", time.Now(), time.Minute))
+
+		Expect(events[1].Operation).To(Equal("bind"))
+		Expect(events[1].Details["binding_id"]).To(Equal("binding-id"))
+	})
+
+	It("appends failed operations", func() {
+		fakeServiceBroker.ProvisionError = errors.New("something went wrong")
+		provision()
+
+		events, err := eventLog.Events(instanceID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].State).To(Equal(brokerapi.OperationFailed))
+		Expect(events[0].Details).To(Equal(map[string]string{
+			"status_code": "500",
+			"description": "something went wrong",
+		}))
+	})
+
+	It("serves the event history of an instance", func() {
+		provision()
+		makeRequest("DELETE", "/v2/service_instances/"+instanceID, "")
+
+		response := makeRequest("GET", "/admin/service_instances/"+instanceID+"/events", "")
+		Expect(response.Code).To(Equal(200))
+
+		var eventsResponse brokerapi.EventsResponse
+		err := json.Unmarshal(response.Body.Bytes(), &eventsResponse)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(eventsResponse.Events).To(HaveLen(2))
+		Expect(eventsResponse.Events[0].Operation).To(Equal("provision"))
+		Expect(eventsResponse.Events[1].Operation).To(Equal("deprovision"))
+	})
+
+	It("serves an empty history for unknown instances", func() {
+		response := makeRequest("GET", "/admin/service_instances/unknown/events", "")
+		Expect(response.Code).To(Equal(200))
+		Expect(response.Body.String()).To(MatchJSON(`{"events":[]}`))
+	})
+
+	It("does not serve the events endpoint without an event log", func() {
+		brokerAPI = brokerapi.New(fakeServiceBroker, lagertest.NewTestLogger("broker-api"), credentials)
+		response := makeRequest("GET", "/admin/service_instances/"+instanceID+"/events", "")
+		Expect(response.Code).To(Equal(404))
+	})
+})
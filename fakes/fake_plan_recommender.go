@@ -0,0 +1,16 @@
+package fakes
+
+import "github.com/pivotal-cf/brokerapi"
+
+type FakePlanRecommender struct {
+	RecommendedHints []brokerapi.PlanHints
+
+	Plans          []brokerapi.ServicePlan
+	RecommendError error
+}
+
+func (recommender *FakePlanRecommender) Recommend(hints brokerapi.PlanHints) ([]brokerapi.ServicePlan, error) {
+	recommender.RecommendedHints = append(recommender.RecommendedHints, hints)
+
+	return recommender.Plans, recommender.RecommendError
+}
@@ -0,0 +1,24 @@
+package fakes
+
+import (
+	"time"
+
+	"github.com/pivotal-cf/brokerapi"
+)
+
+type FakePricingCalculator struct {
+	CalculatedPlanID   string
+	CalculatedQuantity int
+	CalculatedDuration time.Duration
+
+	Price               brokerapi.Price
+	CalculatePriceError error
+}
+
+func (calculator *FakePricingCalculator) CalculatePrice(planID string, quantity int, duration time.Duration) (brokerapi.Price, error) {
+	calculator.CalculatedPlanID = planID
+	calculator.CalculatedQuantity = quantity
+	calculator.CalculatedDuration = duration
+
+	return calculator.Price, calculator.CalculatePriceError
+}
@@ -0,0 +1,17 @@
+package fakes
+
+type FakeResourceChecker struct {
+	CheckedOrganizationGUID string
+	CheckedPlanID           string
+	CheckedQuotas           map[string]int
+
+	CheckQuotaError error
+}
+
+func (checker *FakeResourceChecker) CheckQuota(organizationGUID, planID string, quotas map[string]int) error {
+	checker.CheckedOrganizationGUID = organizationGUID
+	checker.CheckedPlanID = planID
+	checker.CheckedQuotas = quotas
+
+	return checker.CheckQuotaError
+}
@@ -0,0 +1,23 @@
+package fakes
+
+type FakeRestorableServiceBroker struct {
+	FakeServiceBroker
+
+	RestoredInstanceIDs []string
+	RestoredBackupIDs   []string
+
+	RestoreError error
+}
+
+func (fakeBroker *FakeRestorableServiceBroker) RestoreInstance(instanceID, backupID string) error {
+	fakeBroker.BrokerCalled = true
+
+	if fakeBroker.RestoreError != nil {
+		return fakeBroker.RestoreError
+	}
+
+	fakeBroker.RestoredInstanceIDs = append(fakeBroker.RestoredInstanceIDs, instanceID)
+	fakeBroker.RestoredBackupIDs = append(fakeBroker.RestoredBackupIDs, backupID)
+
+	return nil
+}
@@ -18,11 +18,18 @@ type FakeServiceBroker struct {
 	DeprovisionError error
 
 	BrokerCalled bool
+
+	Catalog     []brokerapi.Service
+	Credentials interface{}
 }
 
 func (fakeBroker *FakeServiceBroker) Services() []brokerapi.Service {
 	fakeBroker.BrokerCalled = true
 
+	if fakeBroker.Catalog != nil {
+		return fakeBroker.Catalog
+	}
+
 	return []brokerapi.Service{
 		brokerapi.Service{
 			ID:          "0A789746-596F-4CEA-BFAC-A0795DA056E3",
@@ -88,9 +95,13 @@ func (fakeBroker *FakeServiceBroker) Deprovision(instanceID string) error {
 		return fakeBroker.DeprovisionError
 	}
 
+	alreadyDeprovisioned := sliceContains(instanceID, fakeBroker.DeprovisionedInstanceIDs)
 	fakeBroker.DeprovisionedInstanceIDs = append(fakeBroker.DeprovisionedInstanceIDs, instanceID)
 
 	if sliceContains(instanceID, fakeBroker.ProvisionedInstanceIDs) {
+		if alreadyDeprovisioned {
+			return brokerapi.ErrInstanceGone
+		}
 		return nil
 	}
 	return brokerapi.ErrInstanceDoesNotExist
@@ -100,12 +111,16 @@ func (fakeBroker *FakeServiceBroker) Bind(instanceID, bindingID string) (interfa
 	fakeBroker.BrokerCalled = true
 
 	if fakeBroker.BindError != nil {
-		return nil, fakeBroker.BindError
+		return fakeBroker.Credentials, fakeBroker.BindError
 	}
 
 	fakeBroker.BoundInstanceIDs = append(fakeBroker.BoundInstanceIDs, instanceID)
 	fakeBroker.BoundBindingIDs = append(fakeBroker.BoundBindingIDs, bindingID)
 
+	if fakeBroker.Credentials != nil {
+		return fakeBroker.Credentials, nil
+	}
+
 	return FakeCredentials{
 		Host:     "127.0.0.1",
 		Port:     3000,
@@ -0,0 +1,36 @@
+package fakes
+
+type FakeTaggableServiceBroker struct {
+	FakeServiceBroker
+
+	TaggedInstanceIDs   []string
+	Tags                map[string]string
+	UntaggedInstanceIDs []string
+	RemovedTagKeys      []string
+
+	TagError error
+}
+
+func (fakeBroker *FakeTaggableServiceBroker) TagInstance(instanceID string, tags map[string]string) error {
+	fakeBroker.BrokerCalled = true
+
+	if fakeBroker.TagError != nil {
+		return fakeBroker.TagError
+	}
+
+	fakeBroker.TaggedInstanceIDs = append(fakeBroker.TaggedInstanceIDs, instanceID)
+	fakeBroker.Tags = tags
+	return nil
+}
+
+func (fakeBroker *FakeTaggableServiceBroker) UntagInstance(instanceID, key string) error {
+	fakeBroker.BrokerCalled = true
+
+	if fakeBroker.TagError != nil {
+		return fakeBroker.TagError
+	}
+
+	fakeBroker.UntaggedInstanceIDs = append(fakeBroker.UntaggedInstanceIDs, instanceID)
+	fakeBroker.RemovedTagKeys = append(fakeBroker.RemovedTagKeys, key)
+	return nil
+}
@@ -0,0 +1,73 @@
+package brokerapi
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/pivotal-golang/lager"
+)
+
+const headersTooLargeErrorKey = "headers-too-large"
+const suspiciousHeaderLogKey = "suspicious-header"
+
+const statusRequestHeaderFieldsTooLarge = 431
+
+var errHeadersTooLarge = errors.New("request headers are too large")
+
+var expectedSensitiveHeaders = map[string]bool{
+	"Authorization": true,
+}
+
+var sensitiveHeaderFragments = []string{"auth", "token", "secret", "password", "cookie", "api-key", "apikey", "credential"}
+
+func wrapHeaderAudit(handler http.Handler, maxHeaderBytes int, logger lager.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		data := lager.Data{
+			"method": req.Method,
+			"path":   req.URL.Path,
+		}
+
+		if maxHeaderBytes > 0 && headerSize(req.Header) > maxHeaderBytes {
+			logger.Error(headersTooLargeErrorKey, errHeadersTooLarge, data)
+			respond(w, statusRequestHeaderFieldsTooLarge, ErrorResponse{
+				Description: errHeadersTooLarge.Error(),
+			})
+			return
+		}
+
+		for name := range req.Header {
+			if suspiciousHeader(name) {
+				data["header"] = name
+				logger.Info(suspiciousHeaderLogKey, data)
+				req.Header.Del(name)
+			}
+		}
+
+		handler.ServeHTTP(w, req)
+	})
+}
+
+func headerSize(header http.Header) int {
+	size := 0
+	for name, values := range header {
+		for _, value := range values {
+			size += len(name) + len(": ") + len(value) + len("\r\n")
+		}
+	}
+	return size
+}
+
+func suspiciousHeader(name string) bool {
+	if expectedSensitiveHeaders[name] {
+		return false
+	}
+
+	lowerName := strings.ToLower(name)
+	for _, fragment := range sensitiveHeaderFragments {
+		if strings.Contains(lowerName, fragment) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package brokerapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/brokerapi"
+	"github.com/pivotal-cf/brokerapi/fakes"
+)
+
+var _ = Describe("Header audit", func() {
+	var brokerLogger *lagertest.TestLogger
+	var brokerAPI http.Handler
+	var credentials = brokerapi.BrokerCredentials{
+		Username: "username",
+		Password: "password",
+	}
+
+	makeCatalogRequest := func(header http.Header) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("GET", "/v2/catalog", strings.NewReader(""))
+		for name, values := range header {
+			request.Header[name] = values
+		}
+		request.SetBasicAuth(credentials.Username, credentials.Password)
+		brokerAPI.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	logsWithMessage := func(message string) []lager.LogFormat {
+		logs := []lager.LogFormat{}
+		for _, log := range brokerLogger.Logs() {
+			if log.Message == message {
+				logs = append(logs, log)
+			}
+		}
+		return logs
+	}
+
+	BeforeEach(func() {
+		brokerLogger = lagertest.NewTestLogger("broker-api")
+		brokerAPI = brokerapi.New(&fakes.FakeServiceBroker{}, brokerLogger, credentials, brokerapi.WithHeaderAudit(1024))
+	})
+
+	It("serves requests with normal headers without logging", func() {
+		response := makeCatalogRequest(http.Header{
+			"X-Broker-Api-Version":              {"2.4"},
+			"X-Broker-Api-Originating-Identity": {"cloudfoundry eyJ1c2VyX2lkIjoiYWRtaW4ifQ=="},
+		})
+		Expect(response.Code).To(Equal(200))
+		Expect(logsWithMessage("broker-api.suspicious-header")).To(BeEmpty())
+	})
+
+	It("rejects requests with oversized headers", func() {
+		response := makeCatalogRequest(http.Header{
+			"X-Padding": {strings.Repeat("a", 2048)},
+		})
+		Expect(response.Code).To(Equal(431))
+		Expect(response.Body.String()).To(MatchJSON(`{"description":"request headers are too large"}`))
+		Expect(logsWithMessage("broker-api.headers-too-large")).To(HaveLen(1))
+	})
+
+	It("logs and strips unexpected sensitive headers", func() {
+		response := makeCatalogRequest(http.Header{
+			"X-Auth-Token": {"some-token"},
+		})
+		Expect(response.Code).To(Equal(200))
+
+		logs := logsWithMessage("broker-api.suspicious-header")
+		Expect(logs).To(HaveLen(1))
+		Expect(logs[0].Data["header"]).To(Equal("X-Auth-Token"))
+		Expect(logs[0].Data).NotTo(ContainElement("some-token"))
+	})
+
+	It("does not limit header size when the limit is zero", func() {
+		brokerAPI = brokerapi.New(&fakes.FakeServiceBroker{}, brokerLogger, credentials, brokerapi.WithHeaderAudit(0))
+		response := makeCatalogRequest(http.Header{
+			"X-Padding": {strings.Repeat("a", 2048)},
+		})
+		Expect(response.Code).To(Equal(200))
+	})
+})
@@ -2,17 +2,25 @@ package brokerapi
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/gorilla/mux"
 )
 
 type httpRouter struct {
 	muxRouter *mux.Router
+	routes    *[]httpRoute
+}
+
+type httpRoute struct {
+	url     string
+	methods []string
 }
 
 func newHttpRouter() httpRouter {
 	return httpRouter{
 		muxRouter: mux.NewRouter(),
+		routes:    &[]httpRoute{},
 	}
 }
 
@@ -21,17 +29,48 @@ func (httpRouter httpRouter) ServeHTTP(w http.ResponseWriter, req *http.Request)
 }
 
 func (httpRouter httpRouter) Get(url string, handler http.HandlerFunc) {
-	httpRouter.muxRouter.HandleFunc(url, handler).Methods("GET")
+	httpRouter.handle(url, "GET", handler)
+}
+
+func (httpRouter httpRouter) Post(url string, handler http.HandlerFunc) {
+	httpRouter.handle(url, "POST", handler)
 }
 
 func (httpRouter httpRouter) Put(url string, handler http.HandlerFunc) {
-	httpRouter.muxRouter.HandleFunc(url, handler).Methods("PUT")
+	httpRouter.handle(url, "PUT", handler)
 }
 
 func (httpRouter httpRouter) Delete(url string, handler http.HandlerFunc) {
-	httpRouter.muxRouter.HandleFunc(url, handler).Methods("DELETE")
+	httpRouter.handle(url, "DELETE", handler)
+}
+
+func (httpRouter httpRouter) Options(url string, handler http.HandlerFunc) {
+	httpRouter.muxRouter.HandleFunc(url, handler).Methods("OPTIONS")
 }
 
 func (httpRouter) Vars(req *http.Request) map[string]string {
 	return mux.Vars(req)
 }
+
+func (httpRouter httpRouter) AnswerOptions() {
+	for _, route := range *httpRouter.routes {
+		allow := strings.Join(append(route.methods, "OPTIONS"), ", ")
+		httpRouter.muxRouter.HandleFunc(route.url, func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("Allow", allow)
+			w.WriteHeader(http.StatusNoContent)
+		}).Methods("OPTIONS")
+	}
+}
+
+func (httpRouter httpRouter) handle(url, method string, handler http.HandlerFunc) {
+	httpRouter.muxRouter.HandleFunc(url, handler).Methods(method)
+
+	routes := *httpRouter.routes
+	for i := range routes {
+		if routes[i].url == url {
+			routes[i].methods = append(routes[i].methods, method)
+			return
+		}
+	}
+	*httpRouter.routes = append(routes, httpRoute{url: url, methods: []string{method}})
+}
@@ -0,0 +1,98 @@
+package brokerapi
+
+import "errors"
+
+const maximumInstancesReachedErrorKey = "maximum-instances-reached"
+const minimumInstancesReservedErrorKey = "minimum-instances-reserved"
+
+var ErrMaximumInstancesReached = errors.New("maximum instances reached for this service")
+var errMinimumInstancesReserved = errors.New("cannot reduce below minimum reserved instances")
+var errInstanceLimitsWithoutReserver = errors.New("instance limits require a store that implements InstanceReserver")
+
+type InstanceReserver interface {
+	ReserveInstance(instanceID, serviceID string, maximumInstances int) error
+	ReleaseInstance(instanceID string) error
+}
+
+type PlanInstanceCounter interface {
+	CountPlanInstances(planID string) (int, error)
+}
+
+func (store *memoryStore) ReserveInstance(instanceID, serviceID string, maximumInstances int) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	count := 0
+	for _, instance := range store.instances {
+		if instance.serviceDetails.ID == serviceID {
+			count++
+		}
+	}
+	for reservedInstanceID, reservedServiceID := range store.reservations {
+		if _, ok := store.instances[reservedInstanceID]; !ok && reservedServiceID == serviceID {
+			count++
+		}
+	}
+
+	if count >= maximumInstances {
+		return ErrMaximumInstancesReached
+	}
+
+	store.reservations[instanceID] = serviceID
+	return nil
+}
+
+func (store *memoryStore) ReleaseInstance(instanceID string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	delete(store.reservations, instanceID)
+	return nil
+}
+
+func (store *memoryStore) CountPlanInstances(planID string) (int, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	count := 0
+	for _, instance := range store.instances {
+		if instance.serviceDetails.PlanID == planID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func reserveInstance(services []Service, instanceID, serviceID string, reserver InstanceReserver) (bool, error) {
+	service, found := findService(services, serviceID)
+	if !found || service.MaximumInstances == nil {
+		return false, nil
+	}
+
+	if err := reserver.ReserveInstance(instanceID, serviceID, *service.MaximumInstances); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func checkMinimumInstances(catalog *requestCatalog, store Store, counter PlanInstanceCounter, instanceID string) error {
+	serviceDetails, err := store.FindInstance(instanceID)
+	if err != nil {
+		return nil
+	}
+
+	plan, found := findPlan(catalog.Services(), serviceDetails.PlanID)
+	if !found || plan.MinimumInstances <= 0 {
+		return nil
+	}
+
+	count, err := counter.CountPlanInstances(serviceDetails.PlanID)
+	if err != nil {
+		return err
+	}
+
+	if count <= plan.MinimumInstances {
+		return errMinimumInstancesReserved
+	}
+	return nil
+}
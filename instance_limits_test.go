@@ -0,0 +1,279 @@
+package brokerapi_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/brokerapi"
+	"github.com/pivotal-cf/brokerapi/fakes"
+)
+
+type slowProvisionBroker struct {
+	fakes.FakeServiceBroker
+
+	catalog        []brokerapi.Service
+	provisionError error
+	started        chan string
+	release        chan struct{}
+}
+
+func (broker *slowProvisionBroker) Services() []brokerapi.Service {
+	return broker.catalog
+}
+
+func (broker *slowProvisionBroker) Provision(instanceID string, serviceDetails brokerapi.ServiceDetails) error {
+	broker.started <- instanceID
+	<-broker.release
+	return broker.provisionError
+}
+
+type countlessStore struct {
+	brokerapi.Store
+}
+
+type reservingStore struct {
+	brokerapi.Store
+}
+
+func (store reservingStore) ReserveInstance(instanceID, serviceID string, maximumInstances int) error {
+	return store.Store.(brokerapi.InstanceReserver).ReserveInstance(instanceID, serviceID, maximumInstances)
+}
+
+func (store reservingStore) ReleaseInstance(instanceID string) error {
+	return store.Store.(brokerapi.InstanceReserver).ReleaseInstance(instanceID)
+}
+
+var _ = Describe("Maximum instances", func() {
+	var fakeServiceBroker *fakes.FakeServiceBroker
+	var brokerAPI http.Handler
+	var credentials = brokerapi.BrokerCredentials{
+		Username: "username",
+		Password: "password",
+	}
+
+	makeRequest := func(method, instanceID, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(method, "/v2/service_instances/"+instanceID, strings.NewReader(body))
+		request.SetBasicAuth(credentials.Username, credentials.Password)
+		brokerAPI.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	provision := func(instanceID, serviceID string) *httptest.ResponseRecorder {
+		return makeRequest("PUT", instanceID, `{"service_id":"`+serviceID+`","plan_id":"plan-id"}`)
+	}
+
+	newBrokerAPI := func(maximumInstances *int, opts ...brokerapi.Option) http.Handler {
+		fakeServiceBroker = &fakes.FakeServiceBroker{
+			InstanceLimit: 10,
+			Catalog: []brokerapi.Service{
+				{
+					ID:               "limited-service-id",
+					Name:             "limited",
+					Plans:            []brokerapi.ServicePlan{{ID: "plan-id", Name: "default"}},
+					MaximumInstances: maximumInstances,
+				},
+				{
+					ID:    "other-service-id",
+					Name:  "other",
+					Plans: []brokerapi.ServicePlan{{ID: "other-plan-id", Name: "default"}},
+				},
+			},
+		}
+		return brokerapi.New(fakeServiceBroker, lagertest.NewTestLogger("broker-api"), credentials, opts...)
+	}
+
+	Context("when the service has a maximum", func() {
+		BeforeEach(func() {
+			maximum := 2
+			brokerAPI = newBrokerAPI(&maximum, brokerapi.WithStore(brokerapi.NewMemoryStore()), brokerapi.WithInstanceLimits())
+		})
+
+		It("provisions instances up to the maximum", func() {
+			Expect(provision("instance-1", "limited-service-id").Code).To(Equal(201))
+			Expect(provision("instance-2", "limited-service-id").Code).To(Equal(201))
+		})
+
+		It("returns 422 once the maximum is reached", func() {
+			provision("instance-1", "limited-service-id")
+			provision("instance-2", "limited-service-id")
+
+			response := provision("instance-3", "limited-service-id")
+			Expect(response.Code).To(Equal(422))
+			Expect(response.Body.String()).To(MatchJSON(`{"description":"maximum instances reached for this service"}`))
+			Expect(fakeServiceBroker.ProvisionedInstanceIDs).To(HaveLen(2))
+		})
+
+		It("allows provisioning again after an instance is deprovisioned", func() {
+			provision("instance-1", "limited-service-id")
+			provision("instance-2", "limited-service-id")
+			Expect(makeRequest("DELETE", "instance-1", "").Code).To(Equal(200))
+
+			Expect(provision("instance-3", "limited-service-id").Code).To(Equal(201))
+		})
+
+		It("only counts instances of the same service", func() {
+			provision("instance-1", "other-service-id")
+			provision("instance-2", "other-service-id")
+
+			Expect(provision("instance-3", "limited-service-id").Code).To(Equal(201))
+		})
+	})
+
+	Context("when provisions race for the last instance", func() {
+		var serviceBroker *slowProvisionBroker
+
+		BeforeEach(func() {
+			maximum := 1
+			serviceBroker = &slowProvisionBroker{
+				catalog: []brokerapi.Service{{
+					ID:               "limited-service-id",
+					Plans:            []brokerapi.ServicePlan{{ID: "plan-id"}},
+					MaximumInstances: &maximum,
+				}},
+				started: make(chan string, 2),
+				release: make(chan struct{}),
+			}
+			brokerAPI = brokerapi.New(serviceBroker, lagertest.NewTestLogger("broker-api"), credentials, brokerapi.WithStore(brokerapi.NewMemoryStore()), brokerapi.WithInstanceLimits())
+		})
+
+		It("reserves the instance before provisioning so only one succeeds", func() {
+			first := make(chan int, 1)
+			go func() {
+				defer GinkgoRecover()
+				first <- provision("instance-1", "limited-service-id").Code
+			}()
+			Eventually(serviceBroker.started).Should(Receive())
+
+			Expect(provision("instance-2", "limited-service-id").Code).To(Equal(422))
+
+			close(serviceBroker.release)
+			Eventually(first).Should(Receive(Equal(201)))
+		})
+
+		It("releases the reservation when provisioning fails", func() {
+			serviceBroker.provisionError = errors.New("provision failed")
+			close(serviceBroker.release)
+
+			Expect(provision("instance-1", "limited-service-id").Code).To(Equal(500))
+
+			serviceBroker.provisionError = nil
+			Expect(provision("instance-2", "limited-service-id").Code).To(Equal(201))
+		})
+	})
+
+	Context("when instance limits are enabled without a store that can reserve instances", func() {
+		It("returns an error from NewHandler", func() {
+			_, err := brokerapi.NewHandler(&fakes.FakeServiceBroker{}, lagertest.NewTestLogger("broker-api"), credentials, brokerapi.WithInstanceLimits())
+			Expect(err).To(MatchError("instance limits require a store that implements InstanceReserver"))
+
+			_, err = brokerapi.NewHandler(&fakes.FakeServiceBroker{}, lagertest.NewTestLogger("broker-api"), credentials, brokerapi.WithStore(countlessStore{brokerapi.NewMemoryStore()}), brokerapi.WithInstanceLimits())
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("logs the error from New and serves requests without limits", func() {
+			logger := lagertest.NewTestLogger("broker-api")
+			maximum := 0
+			newBrokerAPI(&maximum)
+			brokerAPI = brokerapi.New(fakeServiceBroker, logger, credentials, brokerapi.WithInstanceLimits())
+
+			Expect(logger.Logs()[0].Message).To(Equal("broker-api.invalid-configuration"))
+			Expect(provision("instance-1", "limited-service-id").Code).To(Equal(201))
+		})
+	})
+
+	Context("when the service has no maximum", func() {
+		BeforeEach(func() {
+			brokerAPI = newBrokerAPI(nil, brokerapi.WithStore(brokerapi.NewMemoryStore()), brokerapi.WithInstanceLimits())
+		})
+
+		It("does not limit instances", func() {
+			for _, instanceID := range []string{"instance-1", "instance-2", "instance-3"} {
+				Expect(provision(instanceID, "limited-service-id").Code).To(Equal(201))
+			}
+		})
+	})
+})
+
+var _ = Describe("Minimum instances", func() {
+	var fakeServiceBroker *fakes.FakeServiceBroker
+	var brokerAPI http.Handler
+	var credentials = brokerapi.BrokerCredentials{
+		Username: "username",
+		Password: "password",
+	}
+
+	makeRequest := func(method, instanceID, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(method, "/v2/service_instances/"+instanceID, strings.NewReader(body))
+		request.SetBasicAuth(credentials.Username, credentials.Password)
+		brokerAPI.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	provision := func(instanceID, planID string) {
+		response := makeRequest("PUT", instanceID, `{"service_id":"service-id","plan_id":"`+planID+`"}`)
+		Expect(response.Code).To(Equal(201))
+	}
+
+	deprovision := func(instanceID string) *httptest.ResponseRecorder {
+		return makeRequest("DELETE", instanceID, "")
+	}
+
+	BeforeEach(func() {
+		fakeServiceBroker = &fakes.FakeServiceBroker{
+			InstanceLimit: 10,
+			Catalog: []brokerapi.Service{
+				{
+					ID:   "service-id",
+					Name: "cluster",
+					Plans: []brokerapi.ServicePlan{
+						{ID: "reserved-plan-id", Name: "reserved", MinimumInstances: 2},
+						{ID: "plan-id", Name: "default"},
+					},
+				},
+			},
+		}
+		brokerAPI = brokerapi.New(fakeServiceBroker, lagertest.NewTestLogger("broker-api"), credentials, brokerapi.WithStore(brokerapi.NewMemoryStore()), brokerapi.WithInstanceLimits())
+
+		provision("instance-1", "reserved-plan-id")
+		provision("instance-2", "reserved-plan-id")
+		provision("instance-3", "reserved-plan-id")
+	})
+
+	It("deprovisions instances down to the minimum", func() {
+		Expect(deprovision("instance-1").Code).To(Equal(200))
+	})
+
+	It("returns 409 when a deprovision would go below the minimum", func() {
+		deprovision("instance-1")
+
+		response := deprovision("instance-2")
+		Expect(response.Code).To(Equal(409))
+		Expect(response.Body.String()).To(MatchJSON(`{"description":"cannot reduce below minimum reserved instances"}`))
+		Expect(fakeServiceBroker.DeprovisionedInstanceIDs).To(Equal([]string{"instance-1"}))
+	})
+
+	It("does not reserve instances of plans without a minimum", func() {
+		provision("instance-4", "plan-id")
+		Expect(deprovision("instance-4").Code).To(Equal(200))
+	})
+
+	It("does not enforce minimums when the store can't count plan instances", func() {
+		brokerAPI = brokerapi.New(fakeServiceBroker, lagertest.NewTestLogger("broker-api"), credentials, brokerapi.WithStore(reservingStore{brokerapi.NewMemoryStore()}), brokerapi.WithInstanceLimits())
+
+		provision("instance-4", "reserved-plan-id")
+		Expect(deprovision("instance-4").Code).To(Equal(200))
+	})
+
+	It("still reports missing instances", func() {
+		Expect(deprovision("missing-instance").Code).To(Equal(410))
+	})
+})
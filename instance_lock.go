@@ -0,0 +1,59 @@
+package brokerapi
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/pivotal-golang/lager"
+)
+
+type instanceLocks struct {
+	mutex  sync.Mutex
+	locked map[string]bool
+}
+
+func newInstanceLocks() *instanceLocks {
+	return &instanceLocks{
+		locked: map[string]bool{},
+	}
+}
+
+func (locks *instanceLocks) tryLock(instanceID string) bool {
+	locks.mutex.Lock()
+	defer locks.mutex.Unlock()
+
+	if locks.locked[instanceID] {
+		return false
+	}
+	locks.locked[instanceID] = true
+	return true
+}
+
+func (locks *instanceLocks) unlock(instanceID string) {
+	locks.mutex.Lock()
+	defer locks.mutex.Unlock()
+
+	delete(locks.locked, instanceID)
+}
+
+func lockInstance(handler http.HandlerFunc, locks *instanceLocks, router httpRouter, logger lager.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		instanceID := router.Vars(req)["instance_id"]
+
+		if !locks.tryLock(instanceID) {
+			logger.Error(concurrentOperationErrorKey, ErrConcurrentOperation, lager.Data{
+				instanceIDLogKey: instanceID,
+				"method":         req.Method,
+				"path":           req.URL.Path,
+			})
+			respond(w, statusUnprocessableEntity, ErrorResponse{
+				Error:       ErrorCodeConcurrencyError,
+				Description: ErrConcurrentOperation.Error(),
+			})
+			return
+		}
+		defer locks.unlock(instanceID)
+
+		handler(w, req)
+	}
+}
@@ -0,0 +1,103 @@
+package brokerapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/brokerapi"
+	"github.com/pivotal-cf/brokerapi/fakes"
+)
+
+type blockingBroker struct {
+	fakes.FakeServiceBroker
+
+	started chan string
+	release chan struct{}
+}
+
+func (broker *blockingBroker) Services() []brokerapi.Service {
+	return nil
+}
+
+func (broker *blockingBroker) Provision(instanceID string, serviceDetails brokerapi.ServiceDetails) error {
+	broker.started <- instanceID
+	<-broker.release
+	return nil
+}
+
+var _ = Describe("Instance locking", func() {
+	var serviceBroker *blockingBroker
+	var brokerAPI http.Handler
+	var credentials = brokerapi.BrokerCredentials{
+		Username: "username",
+		Password: "password",
+	}
+
+	makeProvisionRequest := func(instanceID string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("PUT", "/v2/service_instances/"+instanceID, strings.NewReader(`{"service_id":"service-id","plan_id":"plan-id"}`))
+		request.SetBasicAuth(credentials.Username, credentials.Password)
+		brokerAPI.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	provisionInBackground := func(instanceID string) chan *httptest.ResponseRecorder {
+		responses := make(chan *httptest.ResponseRecorder, 1)
+		go func() {
+			defer GinkgoRecover()
+			responses <- makeProvisionRequest(instanceID)
+		}()
+		return responses
+	}
+
+	BeforeEach(func() {
+		serviceBroker = &blockingBroker{
+			started: make(chan string, 2),
+			release: make(chan struct{}),
+		}
+		brokerAPI = brokerapi.New(serviceBroker, lagertest.NewTestLogger("broker-api"), credentials, brokerapi.WithInstanceLocking())
+	})
+
+	It("rejects a second concurrent operation on the same instance", func() {
+		first := provisionInBackground("instance-id")
+		Eventually(serviceBroker.started).Should(Receive(Equal("instance-id")))
+
+		response := makeProvisionRequest("instance-id")
+		Expect(response.Code).To(Equal(422))
+		Expect(response.Body.String()).To(MatchJSON(`{
+			"error": "ConcurrencyError",
+			"description": "another operation for this service instance is in progress"
+		}`))
+
+		close(serviceBroker.release)
+		var firstResponse *httptest.ResponseRecorder
+		Eventually(first).Should(Receive(&firstResponse))
+		Expect(firstResponse.Code).To(Equal(201))
+	})
+
+	It("allows concurrent operations on different instances", func() {
+		first := provisionInBackground("instance-1")
+		second := provisionInBackground("instance-2")
+		Eventually(serviceBroker.started).Should(Receive())
+		Eventually(serviceBroker.started).Should(Receive())
+
+		close(serviceBroker.release)
+		var response *httptest.ResponseRecorder
+		Eventually(first).Should(Receive(&response))
+		Expect(response.Code).To(Equal(201))
+		Eventually(second).Should(Receive(&response))
+		Expect(response.Code).To(Equal(201))
+	})
+
+	It("releases the lock when the operation finishes", func() {
+		close(serviceBroker.release)
+		Expect(makeProvisionRequest("instance-id").Code).To(Equal(201))
+		Expect(makeProvisionRequest("instance-id").Code).To(Equal(201))
+	})
+})
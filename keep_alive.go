@@ -0,0 +1,22 @@
+package brokerapi
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+func wrapKeepAlive(handler http.Handler, timeout time.Duration) http.Handler {
+	keepAlive := fmt.Sprintf("timeout=%d", int(timeout.Seconds()))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Close {
+			w.Header().Set("Connection", "close")
+		} else {
+			w.Header().Set("Connection", "keep-alive")
+			w.Header().Set("Keep-Alive", keepAlive)
+		}
+
+		handler.ServeHTTP(w, req)
+	})
+}
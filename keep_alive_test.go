@@ -0,0 +1,81 @@
+package brokerapi_test
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/brokerapi"
+	"github.com/pivotal-cf/brokerapi/fakes"
+)
+
+var _ = Describe("Keep-alive", func() {
+	var server *httptest.Server
+
+	newCatalogRequest := func(close bool) *http.Request {
+		request, _ := http.NewRequest("GET", server.URL+"/v2/catalog", nil)
+		request.SetBasicAuth("username", "password")
+		request.Close = close
+		return request
+	}
+
+	BeforeEach(func() {
+		brokerAPI := brokerapi.New(
+			&fakes.FakeServiceBroker{},
+			lagertest.NewTestLogger("broker-api"),
+			brokerapi.BrokerCredentials{Username: "username", Password: "password"},
+			brokerapi.WithKeepAlive(30*time.Second),
+		)
+		server = httptest.NewServer(brokerAPI)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("sets keep-alive headers on responses", func() {
+		response, err := http.DefaultClient.Do(newCatalogRequest(false))
+		Expect(err).NotTo(HaveOccurred())
+		defer response.Body.Close()
+
+		Expect(response.Header.Get("Connection")).To(Equal("keep-alive"))
+		Expect(response.Header.Get("Keep-Alive")).To(Equal("timeout=30"))
+	})
+
+	It("keeps the connection open for sequential requests", func() {
+		conn, err := net.Dial("tcp", server.Listener.Addr().String())
+		Expect(err).NotTo(HaveOccurred())
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+
+		for i := 0; i < 3; i++ {
+			err := newCatalogRequest(false).Write(conn)
+			Expect(err).NotTo(HaveOccurred())
+
+			response, err := http.ReadResponse(reader, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.StatusCode).To(Equal(200))
+			Expect(response.Close).To(BeFalse())
+
+			ioutil.ReadAll(response.Body)
+			response.Body.Close()
+		}
+	})
+
+	It("closes the connection when the client asks to", func() {
+		response, err := http.DefaultClient.Do(newCatalogRequest(true))
+		Expect(err).NotTo(HaveOccurred())
+		defer response.Body.Close()
+
+		Expect(response.Header.Get("Keep-Alive")).To(BeEmpty())
+		Expect(response.Close).To(BeTrue())
+	})
+})
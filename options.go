@@ -0,0 +1,394 @@
+package brokerapi
+
+import (
+	"io"
+	"time"
+
+	"github.com/pivotal-golang/lager"
+)
+
+const defaultCredentialsKey = "credentials"
+
+type Option func(*options)
+
+type options struct {
+	validateCatalog    bool
+	planHealthy        func(planID string) bool
+	extendedEndpoints  bool
+	indentCatalog      bool
+	catalogFiltering   bool
+	catalogProbeHeader string
+	streamingCatalog   bool
+
+	rejectDeprecatedPlanProvisioning bool
+	deprecationSchedule              bool
+	clock                            ClockProvider
+
+	store           Store
+	brokerWrappers  []func(ServiceBroker) ServiceBroker
+	resourceChecker ResourceChecker
+	cors            *CORSConfig
+
+	bodyHashLogging bool
+	accessLogLevels map[string]lager.LogLevel
+	commonLogWriter io.Writer
+
+	queryLogging        bool
+	redactedQueryParams []string
+
+	headerAudit    bool
+	maxHeaderBytes int
+
+	pricingCalculator PricingCalculator
+	planRecommender   PlanRecommender
+
+	answerOptions        bool
+	capabilitiesEndpoint bool
+
+	keepAliveTimeout time.Duration
+	apiVersion       string
+
+	requireOriginatingIdentity bool
+
+	readOnlyMode *ReadOnlyMode
+
+	restoreSupport bool
+	taggingSupport bool
+
+	credentialsKey string
+	errorEnvelope  ErrorEnvelope
+
+	planStateStore PlanStateStore
+
+	eventLog EventLog
+
+	webhookURL    string
+	webhookSecret []byte
+	eventEmitters []EventEmitter
+
+	exclusiveBindResourceServiceIDs []string
+
+	contextValidators []ContextValidator
+
+	maxParameterDepth int
+
+	bindingExpiry *BindingExpiry
+
+	errorLogLevels map[error]lager.LogLevel
+
+	locationHeader bool
+
+	platformDetection bool
+
+	instanceLocking bool
+
+	parameterEcho bool
+
+	bindableCheck bool
+
+	planNameLogging bool
+
+	instanceLimits bool
+}
+
+func newOptions(opts []Option) options {
+	options := options{
+		apiVersion:        DefaultAPIVersion,
+		clock:             systemClock{},
+		credentialsKey:    defaultCredentialsKey,
+		maxParameterDepth: DefaultMaxParameterDepth,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+func WithCatalogValidation() Option {
+	return func(options *options) {
+		options.validateCatalog = true
+	}
+}
+
+func WithPlanHealthCheck(planHealthy func(planID string) bool) Option {
+	return func(options *options) {
+		options.planHealthy = planHealthy
+	}
+}
+
+func WithExtendedEndpoints() Option {
+	return func(options *options) {
+		options.extendedEndpoints = true
+	}
+}
+
+func WithIndentedCatalog() Option {
+	return func(options *options) {
+		options.indentCatalog = true
+	}
+}
+
+func WithExclusiveBindResource(serviceIDs ...string) Option {
+	return func(options *options) {
+		options.exclusiveBindResourceServiceIDs = append(options.exclusiveBindResourceServiceIDs, serviceIDs...)
+	}
+}
+
+func WithRejectDeprecatedPlanProvisioning() Option {
+	return func(options *options) {
+		options.rejectDeprecatedPlanProvisioning = true
+	}
+}
+
+func WithStore(store Store) Option {
+	return func(options *options) {
+		options.store = store
+	}
+}
+
+func WithResourceChecker(checker ResourceChecker) Option {
+	return func(options *options) {
+		options.resourceChecker = checker
+	}
+}
+
+func WithCORS(config CORSConfig) Option {
+	return func(options *options) {
+		options.cors = &config
+	}
+}
+
+func WithBodyHashLogging() Option {
+	return func(options *options) {
+		options.bodyHashLogging = true
+	}
+}
+
+func WithAccessLogLevels(levels map[string]lager.LogLevel) Option {
+	return func(options *options) {
+		options.accessLogLevels = levels
+	}
+}
+
+func (options options) accessLogEnabled() bool {
+	return options.bodyHashLogging || options.accessLogLevels != nil || options.queryLogging || options.platformDetection
+}
+
+func WithPricingEndpoint(calculator PricingCalculator) Option {
+	return func(options *options) {
+		options.pricingCalculator = calculator
+	}
+}
+
+func WithKeepAlive(timeout time.Duration) Option {
+	return func(options *options) {
+		options.keepAliveTimeout = timeout
+	}
+}
+
+func WithAPIVersion(version string) Option {
+	return func(options *options) {
+		options.apiVersion = version
+	}
+}
+
+func WithRequiredOriginatingIdentity() Option {
+	return func(options *options) {
+		options.requireOriginatingIdentity = true
+	}
+}
+
+func WithRestoreSupport() Option {
+	return func(options *options) {
+		options.restoreSupport = true
+	}
+}
+
+func WithCredentialsKey(key string) Option {
+	return func(options *options) {
+		options.credentialsKey = key
+	}
+}
+
+func WithWebhookNotifier(url string, secret []byte) Option {
+	return func(options *options) {
+		options.webhookURL = url
+		options.webhookSecret = secret
+	}
+}
+
+func WithPlanStateStore(store PlanStateStore) Option {
+	return func(options *options) {
+		options.planStateStore = store
+	}
+}
+
+func WithCatalogFiltering() Option {
+	return func(options *options) {
+		options.catalogFiltering = true
+	}
+}
+
+func WithEventLog(log EventLog) Option {
+	return func(options *options) {
+		options.eventLog = log
+	}
+}
+
+func WithOptionsResponses() Option {
+	return func(options *options) {
+		options.answerOptions = true
+	}
+}
+
+func WithOptionsEndpoint() Option {
+	return func(options *options) {
+		options.capabilitiesEndpoint = true
+	}
+}
+
+func WithContextValidators(validators ...ContextValidator) Option {
+	return func(options *options) {
+		options.contextValidators = append(options.contextValidators, validators...)
+	}
+}
+
+func WithReadOnlyMode(mode *ReadOnlyMode) Option {
+	return func(options *options) {
+		options.readOnlyMode = mode
+	}
+}
+
+func WithPlanRecommendation(recommender PlanRecommender) Option {
+	return func(options *options) {
+		options.planRecommender = recommender
+	}
+}
+
+func WithCommonLogFormat(writer io.Writer) Option {
+	return func(options *options) {
+		options.commonLogWriter = writer
+	}
+}
+
+func WithDeprecationSchedule() Option {
+	return func(options *options) {
+		options.deprecationSchedule = true
+	}
+}
+
+func WithClock(clock ClockProvider) Option {
+	return func(options *options) {
+		options.clock = clock
+	}
+}
+
+func WithHeaderAudit(maxHeaderBytes int) Option {
+	return func(options *options) {
+		options.headerAudit = true
+		options.maxHeaderBytes = maxHeaderBytes
+	}
+}
+
+func WithEventEmitter(emitter EventEmitter) Option {
+	return func(options *options) {
+		options.eventEmitters = append(options.eventEmitters, emitter)
+	}
+}
+
+func WithErrorEnvelope(envelope ErrorEnvelope) Option {
+	return func(options *options) {
+		options.errorEnvelope = envelope
+	}
+}
+
+func WithQueryLogging(redactedParams ...string) Option {
+	return func(options *options) {
+		options.queryLogging = true
+		options.redactedQueryParams = redactedParams
+	}
+}
+
+func WithCatalogProbeHeader(header string) Option {
+	return func(options *options) {
+		options.catalogProbeHeader = header
+	}
+}
+
+func WithServiceBrokerWrapper(wrapper func(ServiceBroker) ServiceBroker) Option {
+	return func(options *options) {
+		options.brokerWrappers = append(options.brokerWrappers, wrapper)
+	}
+}
+
+func WithMaxParameterDepth(depth int) Option {
+	return func(options *options) {
+		options.maxParameterDepth = depth
+	}
+}
+
+func WithBindingExpiry(expiry *BindingExpiry) Option {
+	return func(options *options) {
+		options.bindingExpiry = expiry
+	}
+}
+
+func WithErrorLogLevels(levels map[error]lager.LogLevel) Option {
+	return func(options *options) {
+		options.errorLogLevels = levels
+	}
+}
+
+func WithLocationHeader() Option {
+	return func(options *options) {
+		options.locationHeader = true
+	}
+}
+
+func WithPlatformDetection() Option {
+	return func(options *options) {
+		options.platformDetection = true
+	}
+}
+
+func WithInstanceLocking() Option {
+	return func(options *options) {
+		options.instanceLocking = true
+	}
+}
+
+func WithParameterEcho() Option {
+	return func(options *options) {
+		options.parameterEcho = true
+	}
+}
+
+func WithStreamingCatalog() Option {
+	return func(options *options) {
+		options.streamingCatalog = true
+	}
+}
+
+func WithTaggingSupport() Option {
+	return func(options *options) {
+		options.taggingSupport = true
+	}
+}
+
+func WithBindableCheck() Option {
+	return func(options *options) {
+		options.bindableCheck = true
+	}
+}
+
+func WithPlanNameLogging() Option {
+	return func(options *options) {
+		options.planNameLogging = true
+	}
+}
+
+func WithInstanceLimits() Option {
+	return func(options *options) {
+		options.instanceLimits = true
+	}
+}
@@ -0,0 +1,30 @@
+package brokerapi
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/pivotal-golang/lager"
+)
+
+const originatingIdentityHeader = "X-Broker-API-Originating-Identity"
+const originatingIdentityMissingErrorKey = "originating-identity-missing"
+
+var errOriginatingIdentityMissing = errors.New("missing " + originatingIdentityHeader + " header")
+
+func requireOriginatingIdentity(handler http.HandlerFunc, logger lager.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get(originatingIdentityHeader) == "" {
+			logger.Error(originatingIdentityMissingErrorKey, errOriginatingIdentityMissing, lager.Data{
+				"method": req.Method,
+				"path":   req.URL.Path,
+			})
+			respond(w, http.StatusBadRequest, ErrorResponse{
+				Description: errOriginatingIdentityMissing.Error(),
+			})
+			return
+		}
+
+		handler(w, req)
+	}
+}
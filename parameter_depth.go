@@ -0,0 +1,39 @@
+package brokerapi
+
+import "fmt"
+
+const DefaultMaxParameterDepth = 32
+
+const parametersTooDeepErrorKey = "parameters-too-deep"
+
+func checkParameterDepth(parameters map[string]interface{}, maxDepth int) error {
+	if maxDepth <= 0 || parameters == nil {
+		return nil
+	}
+
+	if valueDepth(parameters) > maxDepth {
+		return fmt.Errorf("parameters exceed the maximum nesting depth of %d", maxDepth)
+	}
+	return nil
+}
+
+func valueDepth(value interface{}) int {
+	deepest := 0
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for _, child := range value {
+			if depth := valueDepth(child); depth > deepest {
+				deepest = depth
+			}
+		}
+	case []interface{}:
+		for _, child := range value {
+			if depth := valueDepth(child); depth > deepest {
+				deepest = depth
+			}
+		}
+	default:
+		return 0
+	}
+	return deepest + 1
+}
@@ -0,0 +1,96 @@
+package brokerapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/brokerapi"
+	"github.com/pivotal-cf/brokerapi/fakes"
+)
+
+var _ = Describe("Parameter nesting depth", func() {
+	var fakeServiceBroker *fakes.FakeServiceBroker
+	var brokerAPI http.Handler
+	var credentials = brokerapi.BrokerCredentials{
+		Username: "username",
+		Password: "password",
+	}
+
+	nestedParameters := func(depth int) string {
+		return strings.Repeat(`{"a":`, depth-1) + `{"a":1}` + strings.Repeat(`}`, depth-1)
+	}
+
+	makeProvisionRequest := func(parameters string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		body := `{"service_id":"service-id","plan_id":"plan-id","parameters":` + parameters + `}`
+		request, _ := http.NewRequest("PUT", "/v2/service_instances/instance-id", strings.NewReader(body))
+		request.SetBasicAuth(credentials.Username, credentials.Password)
+		brokerAPI.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	newBrokerAPI := func(opts ...brokerapi.Option) http.Handler {
+		return brokerapi.New(fakeServiceBroker, lagertest.NewTestLogger("broker-api"), credentials, opts...)
+	}
+
+	BeforeEach(func() {
+		fakeServiceBroker = &fakes.FakeServiceBroker{InstanceLimit: 3}
+	})
+
+	Context("with the default limit", func() {
+		BeforeEach(func() {
+			brokerAPI = newBrokerAPI()
+		})
+
+		It("passes the parameters to the broker", func() {
+			response := makeProvisionRequest(`{"size":"large","tags":["a","b"]}`)
+			Expect(response.Code).To(Equal(201))
+			Expect(fakeServiceBroker.ServiceDetails.Parameters).To(Equal(map[string]interface{}{
+				"size": "large",
+				"tags": []interface{}{"a", "b"},
+			}))
+		})
+
+		It("accepts parameters nested up to the limit", func() {
+			response := makeProvisionRequest(nestedParameters(brokerapi.DefaultMaxParameterDepth))
+			Expect(response.Code).To(Equal(201))
+		})
+
+		It("rejects parameters nested beyond the limit", func() {
+			response := makeProvisionRequest(nestedParameters(brokerapi.DefaultMaxParameterDepth + 1))
+			Expect(response.Code).To(Equal(400))
+			Expect(response.Body.String()).To(MatchJSON(`{"description":"parameters exceed the maximum nesting depth of 32"}`))
+			Expect(fakeServiceBroker.ProvisionedInstanceIDs).To(BeEmpty())
+		})
+	})
+
+	Context("with a configured limit", func() {
+		BeforeEach(func() {
+			brokerAPI = newBrokerAPI(brokerapi.WithMaxParameterDepth(2))
+		})
+
+		It("counts arrays as a level of nesting", func() {
+			Expect(makeProvisionRequest(`{"tags":["a"]}`).Code).To(Equal(201))
+		})
+
+		It("rejects parameters nested beyond the limit", func() {
+			Expect(makeProvisionRequest(`{"tags":[{"name":"a"}]}`).Code).To(Equal(400))
+		})
+	})
+
+	Context("when the limit is disabled", func() {
+		BeforeEach(func() {
+			brokerAPI = newBrokerAPI(brokerapi.WithMaxParameterDepth(0))
+		})
+
+		It("accepts deeply nested parameters", func() {
+			Expect(makeProvisionRequest(nestedParameters(100)).Code).To(Equal(201))
+		})
+	})
+})
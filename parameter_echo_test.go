@@ -0,0 +1,67 @@
+package brokerapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/brokerapi"
+	"github.com/pivotal-cf/brokerapi/fakes"
+)
+
+var _ = Describe("Parameter echo", func() {
+	var brokerAPI http.Handler
+	var credentials = brokerapi.BrokerCredentials{
+		Username: "username",
+		Password: "password",
+	}
+
+	makeProvisionRequest := func(query string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		body := `{"service_id":"service-id","plan_id":"plan-id","parameters":{"size":"large","replicas":3}}`
+		request, _ := http.NewRequest("PUT", "/v2/service_instances/instance-id"+query, strings.NewReader(body))
+		request.SetBasicAuth(credentials.Username, credentials.Password)
+		brokerAPI.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	newBrokerAPI := func(opts ...brokerapi.Option) http.Handler {
+		fakeServiceBroker := &fakes.FakeServiceBroker{InstanceLimit: 3}
+		return brokerapi.New(fakeServiceBroker, lagertest.NewTestLogger("broker-api"), credentials, opts...)
+	}
+
+	Context("when enabled", func() {
+		BeforeEach(func() {
+			brokerAPI = newBrokerAPI(brokerapi.WithParameterEcho())
+		})
+
+		It("echoes the parameters when requested", func() {
+			response := makeProvisionRequest("?return_parameters=true")
+			Expect(response.Code).To(Equal(201))
+			Expect(response.Body.String()).To(MatchJSON(`{"parameters":{"size":"large","replicas":3}}`))
+		})
+
+		It("does not echo the parameters unless requested", func() {
+			response := makeProvisionRequest("")
+			Expect(response.Code).To(Equal(201))
+			Expect(response.Body.String()).To(MatchJSON(`{}`))
+		})
+	})
+
+	Context("when not enabled", func() {
+		BeforeEach(func() {
+			brokerAPI = newBrokerAPI()
+		})
+
+		It("ignores the query flag", func() {
+			response := makeProvisionRequest("?return_parameters=true")
+			Expect(response.Code).To(Equal(201))
+			Expect(response.Body.String()).To(MatchJSON(`{}`))
+		})
+	})
+})
@@ -0,0 +1,87 @@
+package brokerapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+type PlanDiff struct {
+	Added   []string
+	Removed []string
+	Changed []string
+}
+
+func (diff PlanDiff) Empty() bool {
+	return len(diff.Added) == 0 && len(diff.Removed) == 0 && len(diff.Changed) == 0
+}
+
+func ComparePlans(a, b ServicePlan) PlanDiff {
+	diff := PlanDiff{}
+	diffJSON(&diff, "", planJSON(a), planJSON(b))
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Strings(diff.Changed)
+	return diff
+}
+
+func planJSON(plan ServicePlan) interface{} {
+	data, _ := json.Marshal(plan)
+
+	var value interface{}
+	json.Unmarshal(data, &value)
+	return value
+}
+
+func diffJSON(diff *PlanDiff, path string, a, b interface{}) {
+	switch aValue := a.(type) {
+	case map[string]interface{}:
+		bValue, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+		for key, value := range aValue {
+			if other, found := bValue[key]; found {
+				diffJSON(diff, joinJSONPath(path, key), value, other)
+			} else {
+				diff.Removed = append(diff.Removed, joinJSONPath(path, key))
+			}
+		}
+		for key := range bValue {
+			if _, found := aValue[key]; !found {
+				diff.Added = append(diff.Added, joinJSONPath(path, key))
+			}
+		}
+		return
+	case []interface{}:
+		bValue, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+		for i := 0; i < len(aValue) || i < len(bValue); i++ {
+			elementPath := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(bValue):
+				diff.Removed = append(diff.Removed, elementPath)
+			case i >= len(aValue):
+				diff.Added = append(diff.Added, elementPath)
+			default:
+				diffJSON(diff, elementPath, aValue[i], bValue[i])
+			}
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		diff.Changed = append(diff.Changed, path)
+	}
+}
+
+func joinJSONPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
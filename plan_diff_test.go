@@ -0,0 +1,65 @@
+package brokerapi_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/brokerapi"
+)
+
+var _ = Describe("ComparePlans", func() {
+	var plan brokerapi.ServicePlan
+
+	BeforeEach(func() {
+		plan = brokerapi.ServicePlan{
+			ID:          "plan-id",
+			Name:        "small",
+			Description: "A small plan",
+			Metadata: brokerapi.ServicePlanMetadata{
+				Bullets:     []string{"1 GB storage", "10 connections"},
+				DisplayName: "Small",
+			},
+		}
+	})
+
+	It("returns an empty diff for identical plans", func() {
+		diff := brokerapi.ComparePlans(plan, plan)
+		Expect(diff.Empty()).To(BeTrue())
+		Expect(diff).To(Equal(brokerapi.PlanDiff{}))
+	})
+
+	It("reports changed fields by JSON path", func() {
+		other := plan
+		other.Name = "medium"
+		other.Description = "A medium plan"
+		other.Metadata.DisplayName = "Medium"
+		other.Metadata.Bullets = []string{"5 GB storage", "10 connections"}
+
+		diff := brokerapi.ComparePlans(plan, other)
+		Expect(diff.Changed).To(Equal([]string{
+			"description",
+			"metadata.bullets[0]",
+			"metadata.displayName",
+			"name",
+		}))
+		Expect(diff.Added).To(BeEmpty())
+		Expect(diff.Removed).To(BeEmpty())
+	})
+
+	It("reports added and removed fields", func() {
+		free := false
+		other := plan
+		other.Free = &free
+		other.Metadata.Deprecated = true
+		other.Metadata.Bullets = []string{"1 GB storage"}
+
+		diff := brokerapi.ComparePlans(plan, other)
+		Expect(diff.Added).To(Equal([]string{"free", "metadata.deprecated"}))
+		Expect(diff.Removed).To(Equal([]string{"metadata.bullets[1]"}))
+		Expect(diff.Changed).To(BeEmpty())
+
+		reverse := brokerapi.ComparePlans(other, plan)
+		Expect(reverse.Added).To(Equal(diff.Removed))
+		Expect(reverse.Removed).To(Equal(diff.Added))
+	})
+})
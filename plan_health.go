@@ -0,0 +1,23 @@
+package brokerapi
+
+func healthyServices(services []Service, planHealthy func(planID string) bool) []Service {
+	healthy := []Service{}
+
+	for _, service := range services {
+		plans := []ServicePlan{}
+		for _, plan := range service.Plans {
+			if planHealthy(plan.ID) {
+				plans = append(plans, plan)
+			}
+		}
+
+		if len(plans) == 0 {
+			continue
+		}
+
+		service.Plans = plans
+		healthy = append(healthy, service)
+	}
+
+	return healthy
+}
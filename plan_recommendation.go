@@ -0,0 +1,55 @@
+package brokerapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pivotal-golang/lager"
+)
+
+const recommendLogKey = "recommend"
+const invalidPlanHintsErrorKey = "invalid-plan-hints"
+
+type PlanRecommender interface {
+	Recommend(hints PlanHints) ([]ServicePlan, error)
+}
+
+type PlanHints struct {
+	ExpectedConnections int  `json:"expected_connections"`
+	StorageGB           int  `json:"storage_gb"`
+	HARequired          bool `json:"ha_required"`
+}
+
+func recommend(recommender PlanRecommender, router httpRouter, logger lager.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		logger := logger.Session(recommendLogKey)
+
+		var hints PlanHints
+		if err := json.NewDecoder(req.Body).Decode(&hints); err != nil {
+			logger.Error(invalidPlanHintsErrorKey, err)
+			respond(w, statusUnprocessableEntity, ErrorResponse{
+				Description: err.Error(),
+			})
+			return
+		}
+
+		logger = logger.WithData(lager.Data{
+			"hints": hints,
+		})
+
+		plans, err := recommender.Recommend(hints)
+		if err != nil {
+			logger.Error(unknownErrorKey, err)
+			respond(w, http.StatusInternalServerError, ErrorResponse{
+				Description: err.Error(),
+			})
+			return
+		}
+
+		if plans == nil {
+			plans = []ServicePlan{}
+		}
+
+		respond(w, http.StatusOK, newServicePlanListResponse(plans, 0, 0))
+	}
+}
@@ -0,0 +1,94 @@
+package brokerapi_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/brokerapi"
+	"github.com/pivotal-cf/brokerapi/fakes"
+)
+
+var _ = Describe("Plan recommendation endpoint", func() {
+	var recommender *fakes.FakePlanRecommender
+	var brokerAPI http.Handler
+	var credentials = brokerapi.BrokerCredentials{
+		Username: "username",
+		Password: "password",
+	}
+
+	makeRecommendRequest := func(body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("POST", "/v2/service_plans/recommend", strings.NewReader(body))
+		request.SetBasicAuth(credentials.Username, credentials.Password)
+		brokerAPI.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	BeforeEach(func() {
+		recommender = &fakes.FakePlanRecommender{
+			Plans: []brokerapi.ServicePlan{
+				{ID: "ha-large", Name: "ha-large"},
+				{ID: "ha-medium", Name: "ha-medium"},
+				{ID: "large", Name: "large"},
+			},
+		}
+		brokerAPI = brokerapi.New(&fakes.FakeServiceBroker{}, lagertest.NewTestLogger("broker-api"), credentials, brokerapi.WithPlanRecommendation(recommender))
+	})
+
+	It("is not served unless enabled", func() {
+		brokerAPI = brokerapi.New(&fakes.FakeServiceBroker{}, lagertest.NewTestLogger("broker-api"), credentials)
+		response := makeRecommendRequest(`{}`)
+		Expect(response.Code).To(Equal(404))
+	})
+
+	It("passes the usage hints to the recommender", func() {
+		makeRecommendRequest(`{"expected_connections":100,"storage_gb":50,"ha_required":true}`)
+		Expect(recommender.RecommendedHints).To(Equal([]brokerapi.PlanHints{
+			{ExpectedConnections: 100, StorageGB: 50, HARequired: true},
+		}))
+	})
+
+	It("returns the plans in the order they were ranked", func() {
+		response := makeRecommendRequest(`{"expected_connections":100,"storage_gb":50,"ha_required":true}`)
+		Expect(response.Code).To(Equal(200))
+
+		var planList brokerapi.ServicePlanListResponse
+		err := json.Unmarshal(response.Body.Bytes(), &planList)
+		Expect(err).NotTo(HaveOccurred())
+
+		planIDs := []string{}
+		for _, plan := range planList.Plans {
+			planIDs = append(planIDs, plan.ID)
+		}
+		Expect(planIDs).To(Equal([]string{"ha-large", "ha-medium", "large"}))
+		Expect(planList.TotalResults).To(Equal(3))
+	})
+
+	It("returns an empty list when no plan is compatible", func() {
+		recommender.Plans = nil
+		response := makeRecommendRequest(`{"storage_gb":5000}`)
+		Expect(response.Code).To(Equal(200))
+		Expect(response.Body.String()).To(MatchJSON(`{"plans":[],"total_results":0,"total_pages":0}`))
+	})
+
+	It("returns a 422 for malformed hints", func() {
+		response := makeRecommendRequest(`{"storage_gb":"lots"}`)
+		Expect(response.Code).To(Equal(422))
+		Expect(recommender.RecommendedHints).To(BeEmpty())
+	})
+
+	It("returns a 500 when the recommender fails", func() {
+		recommender.RecommendError = errors.New("recommender unavailable")
+		response := makeRecommendRequest(`{}`)
+		Expect(response.Code).To(Equal(500))
+		Expect(response.Body.String()).To(MatchJSON(`{"description":"recommender unavailable"}`))
+	})
+})
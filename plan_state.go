@@ -0,0 +1,82 @@
+package brokerapi
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/pivotal-golang/lager"
+)
+
+const planStateLogKey = "plan-state"
+const planDisabledErrorKey = "plan-disabled"
+
+var errPlanDisabled = errors.New("this plan is currently disabled")
+
+type PlanStateStore interface {
+	IsEnabled(planID string) bool
+	SetEnabled(planID string, enabled bool)
+}
+
+type memoryPlanStateStore struct {
+	mutex         sync.RWMutex
+	disabledPlans map[string]bool
+}
+
+func NewMemoryPlanStateStore() PlanStateStore {
+	return &memoryPlanStateStore{
+		disabledPlans: map[string]bool{},
+	}
+}
+
+func (store *memoryPlanStateStore) IsEnabled(planID string) bool {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	return !store.disabledPlans[planID]
+}
+
+func (store *memoryPlanStateStore) SetEnabled(planID string, enabled bool) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if enabled {
+		delete(store.disabledPlans, planID)
+	} else {
+		store.disabledPlans[planID] = true
+	}
+}
+
+func planEnabled(services []Service, planID string, store PlanStateStore) bool {
+	if plan, found := findPlan(services, planID); found && plan.PlanEnabled != nil && !*plan.PlanEnabled {
+		return false
+	}
+	if store != nil {
+		return store.IsEnabled(planID)
+	}
+	return true
+}
+
+func setPlanState(serviceBroker ServiceBroker, store PlanStateStore, enabled bool, router httpRouter, logger lager.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		planID := router.Vars(req)["plan_id"]
+
+		logger := logger.Session(planStateLogKey, lager.Data{
+			planIDLogKey: planID,
+			"enabled":    enabled,
+		})
+
+		if _, found := findPlan(serviceBroker.Services(), planID); !found {
+			logger.Error(planMissingErrorKey, errPlanDoesNotExist)
+			respond(w, http.StatusNotFound, ErrorResponse{
+				Description: errPlanDoesNotExist.Error(),
+			})
+			return
+		}
+
+		store.SetEnabled(planID, enabled)
+		logger.Info("updated")
+
+		respond(w, http.StatusOK, EmptyResponse{})
+	}
+}
@@ -0,0 +1,95 @@
+package brokerapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/brokerapi"
+	"github.com/pivotal-cf/brokerapi/fakes"
+)
+
+var _ = Describe("Plan state", func() {
+	const planID = "ABE176EE-F69F-4A96-80CE-142595CC24E3"
+
+	var fakeServiceBroker *fakes.FakeServiceBroker
+	var planStateStore brokerapi.PlanStateStore
+	var brokerAPI http.Handler
+	var credentials = brokerapi.BrokerCredentials{
+		Username: "username",
+		Password: "password",
+	}
+
+	makeRequest := func(method, path, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(method, path, strings.NewReader(body))
+		request.SetBasicAuth(credentials.Username, credentials.Password)
+		brokerAPI.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	provision := func() *httptest.ResponseRecorder {
+		return makeRequest("PUT", "/v2/service_instances/"+uniqueInstanceID(), `{"plan_id":"`+planID+`"}`)
+	}
+
+	BeforeEach(func() {
+		fakeServiceBroker = &fakes.FakeServiceBroker{
+			InstanceLimit: 10,
+		}
+		planStateStore = brokerapi.NewMemoryPlanStateStore()
+		brokerAPI = brokerapi.New(fakeServiceBroker, lagertest.NewTestLogger("broker-api"), credentials, brokerapi.WithPlanStateStore(planStateStore))
+	})
+
+	It("provisions plans that have not been disabled", func() {
+		Expect(provision().Code).To(Equal(201))
+	})
+
+	It("can disable and re-enable a plan without restarting the broker", func() {
+		response := makeRequest("PUT", "/admin/service_plans/"+planID+"/disable", "")
+		Expect(response.Code).To(Equal(200))
+		Expect(planStateStore.IsEnabled(planID)).To(BeFalse())
+
+		response = provision()
+		Expect(response.Code).To(Equal(400))
+		Expect(response.Body.String()).To(MatchJSON(`{"description":"this plan is currently disabled"}`))
+		Expect(fakeServiceBroker.ProvisionedInstanceIDs).To(BeEmpty())
+
+		response = makeRequest("PUT", "/admin/service_plans/"+planID+"/enable", "")
+		Expect(response.Code).To(Equal(200))
+		Expect(provision().Code).To(Equal(201))
+	})
+
+	It("returns a 404 when toggling a plan that is not in the catalog", func() {
+		response := makeRequest("PUT", "/admin/service_plans/unknown-plan/disable", "")
+		Expect(response.Code).To(Equal(404))
+	})
+
+	It("requires broker credentials for the admin endpoints", func() {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("PUT", "/admin/service_plans/"+planID+"/disable", nil)
+		brokerAPI.ServeHTTP(recorder, request)
+		Expect(recorder.Code).To(Equal(401))
+		Expect(planStateStore.IsEnabled(planID)).To(BeTrue())
+	})
+
+	It("does not serve the admin endpoints without a plan state store", func() {
+		brokerAPI = brokerapi.New(fakeServiceBroker, lagertest.NewTestLogger("broker-api"), credentials)
+		response := makeRequest("PUT", "/admin/service_plans/"+planID+"/disable", "")
+		Expect(response.Code).To(Equal(404))
+	})
+
+	It("rejects plans disabled in the catalog", func() {
+		disabled := false
+		services := fakeServiceBroker.Services()
+		services[0].Plans[0].PlanEnabled = &disabled
+		fakeServiceBroker.Catalog = services
+
+		brokerAPI = brokerapi.New(fakeServiceBroker, lagertest.NewTestLogger("broker-api"), credentials)
+		Expect(provision().Code).To(Equal(400))
+	})
+})
@@ -0,0 +1,62 @@
+package brokerapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gorilla/context"
+)
+
+const UnknownPlatform = "unknown"
+
+type contextKey int
+
+const platformContextKey contextKey = iota
+
+type platformRequestBody struct {
+	Context struct {
+		Platform string `json:"platform"`
+	} `json:"context"`
+}
+
+func wrapPlatformDetection(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		defer context.Clear(req)
+
+		context.Set(req, platformContextKey, detectPlatform(req))
+		handler.ServeHTTP(w, req)
+	})
+}
+
+func detectPlatform(req *http.Request) string {
+	if req.Body == nil {
+		return UnknownPlatform
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, maxRequestBodySize+1))
+	req.Body = readCloser{io.MultiReader(bytes.NewReader(body), req.Body), req.Body}
+	if err != nil || len(body) > maxRequestBodySize {
+		return UnknownPlatform
+	}
+
+	var requestBody platformRequestBody
+	if err := json.Unmarshal(body, &requestBody); err != nil || requestBody.Context.Platform == "" {
+		return UnknownPlatform
+	}
+	return requestBody.Context.Platform
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func PlatformFromRequest(req *http.Request) string {
+	if platform, ok := context.Get(req, platformContextKey).(string); ok {
+		return platform
+	}
+	return UnknownPlatform
+}
@@ -0,0 +1,91 @@
+package brokerapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/brokerapi"
+	"github.com/pivotal-cf/brokerapi/fakes"
+)
+
+var _ = Describe("Platform detection", func() {
+	var fakeServiceBroker *fakes.FakeServiceBroker
+	var brokerLogger *lagertest.TestLogger
+	var brokerAPI http.Handler
+	var credentials = brokerapi.BrokerCredentials{
+		Username: "username",
+		Password: "password",
+	}
+
+	makeProvisionRequest := func(body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("PUT", "/v2/service_instances/instance-id", strings.NewReader(body))
+		request.SetBasicAuth(credentials.Username, credentials.Password)
+		brokerAPI.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	requestLog := func() lager.LogFormat {
+		for _, log := range brokerLogger.Logs() {
+			if log.Message == "broker-api.request" {
+				return log
+			}
+		}
+		Fail("no request log line")
+		return lager.LogFormat{}
+	}
+
+	BeforeEach(func() {
+		fakeServiceBroker = &fakes.FakeServiceBroker{InstanceLimit: 3}
+		brokerLogger = lagertest.NewTestLogger("broker-api")
+		brokerAPI = brokerapi.New(fakeServiceBroker, brokerLogger, credentials, brokerapi.WithPlatformDetection())
+	})
+
+	It("logs a Cloud Foundry platform", func() {
+		makeProvisionRequest(`{"service_id":"service-id","plan_id":"plan-id","context":{"platform":"cloudfoundry"}}`)
+		Expect(requestLog().Data["platform"]).To(Equal("cloudfoundry"))
+	})
+
+	It("logs a Kubernetes platform", func() {
+		makeProvisionRequest(`{"service_id":"service-id","plan_id":"plan-id","context":{"platform":"kubernetes"}}`)
+		Expect(requestLog().Data["platform"]).To(Equal("kubernetes"))
+	})
+
+	It("logs an unknown platform when there is no context", func() {
+		makeProvisionRequest(`{"service_id":"service-id","plan_id":"plan-id"}`)
+		Expect(requestLog().Data["platform"]).To(Equal("unknown"))
+	})
+
+	It("logs an unknown platform when the body is not JSON", func() {
+		makeProvisionRequest(`not json`)
+		Expect(requestLog().Data["platform"]).To(Equal("unknown"))
+	})
+
+	It("still passes the request body to the broker", func() {
+		response := makeProvisionRequest(`{"service_id":"service-id","plan_id":"plan-id","context":{"platform":"kubernetes"}}`)
+		Expect(response.Code).To(Equal(201))
+		Expect(fakeServiceBroker.ServiceDetails.Context).To(Equal(map[string]interface{}{"platform": "kubernetes"}))
+	})
+
+	It("stops inspecting bodies larger than 1MB but still passes them to the broker", func() {
+		padding := strings.Repeat("x", 1<<20)
+		response := makeProvisionRequest(`{"service_id":"service-id","plan_id":"plan-id","parameters":{"padding":"` + padding + `"},"context":{"platform":"kubernetes"}}`)
+		Expect(requestLog().Data["platform"]).To(Equal("unknown"))
+		Expect(response.Code).To(Equal(201))
+		Expect(fakeServiceBroker.ServiceDetails.Parameters["padding"]).To(Equal(padding))
+	})
+
+	Describe("PlatformFromRequest", func() {
+		It("returns unknown for requests that were not inspected", func() {
+			request, _ := http.NewRequest("GET", "/v2/catalog", nil)
+			Expect(brokerapi.PlatformFromRequest(request)).To(Equal("unknown"))
+		})
+	})
+})
@@ -0,0 +1,86 @@
+package brokerapi
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pivotal-golang/lager"
+)
+
+const pricingLogKey = "pricing"
+const invalidPricingRequestErrorKey = "invalid-pricing-request"
+const planMissingErrorKey = "plan-missing"
+
+type PricingCalculator interface {
+	CalculatePrice(planID string, quantity int, duration time.Duration) (Price, error)
+}
+
+type Price struct {
+	Amount      float64 `json:"amount"`
+	Currency    string  `json:"currency"`
+	Description string  `json:"description"`
+}
+
+func pricing(serviceBroker ServiceBroker, calculator PricingCalculator, router httpRouter, logger lager.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		planID := router.Vars(req)["plan_id"]
+
+		logger := logger.Session(pricingLogKey, lager.Data{
+			planIDLogKey: planID,
+		})
+
+		quantity, duration, err := parsePricingQuery(req)
+		if err != nil {
+			logger.Error(invalidPricingRequestErrorKey, err)
+			respond(w, http.StatusBadRequest, ErrorResponse{
+				Description: err.Error(),
+			})
+			return
+		}
+
+		if _, found := findPlan(serviceBroker.Services(), planID); !found {
+			logger.Error(planMissingErrorKey, errPlanDoesNotExist)
+			respond(w, http.StatusNotFound, ErrorResponse{
+				Description: errPlanDoesNotExist.Error(),
+			})
+			return
+		}
+
+		price, err := calculator.CalculatePrice(planID, quantity, duration)
+		if err != nil {
+			logger.Error(unknownErrorKey, err)
+			respond(w, http.StatusInternalServerError, ErrorResponse{
+				Description: err.Error(),
+			})
+			return
+		}
+
+		respond(w, http.StatusOK, price)
+	}
+}
+
+func parsePricingQuery(req *http.Request) (int, time.Duration, error) {
+	query := req.URL.Query()
+
+	quantity := 1
+	if rawQuantity := query.Get("quantity"); rawQuantity != "" {
+		parsed, err := strconv.Atoi(rawQuantity)
+		if err != nil || parsed < 1 {
+			return 0, 0, fmt.Errorf("quantity must be a positive integer, got %q", rawQuantity)
+		}
+		quantity = parsed
+	}
+
+	duration := time.Hour
+	if rawDuration := query.Get("duration"); rawDuration != "" {
+		hours, err := strconv.ParseFloat(rawDuration, 64)
+		if err != nil || hours <= 0 {
+			return 0, 0, fmt.Errorf("duration must be a positive number of hours, got %q", rawDuration)
+		}
+		duration = time.Duration(hours * float64(time.Hour))
+	}
+
+	return quantity, duration, nil
+}
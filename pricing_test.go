@@ -0,0 +1,95 @@
+package brokerapi_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/brokerapi"
+	"github.com/pivotal-cf/brokerapi/fakes"
+)
+
+var _ = Describe("Pricing endpoint", func() {
+	var fakeServiceBroker *fakes.FakeServiceBroker
+	var calculator *fakes.FakePricingCalculator
+	var brokerAPI http.Handler
+	var credentials = brokerapi.BrokerCredentials{
+		Username: "username",
+		Password: "password",
+	}
+
+	const planID = "ABE176EE-F69F-4A96-80CE-142595CC24E3"
+
+	makePricingRequest := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("GET", path, nil)
+		request.SetBasicAuth(credentials.Username, credentials.Password)
+		brokerAPI.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	BeforeEach(func() {
+		fakeServiceBroker = &fakes.FakeServiceBroker{}
+		calculator = &fakes.FakePricingCalculator{
+			Price: brokerapi.Price{
+				Amount:      12.5,
+				Currency:    "USD",
+				Description: "2 instances for 5 hours",
+			},
+		}
+		brokerAPI = brokerapi.New(fakeServiceBroker, lagertest.NewTestLogger("broker-api"), credentials, brokerapi.WithPricingEndpoint(calculator))
+	})
+
+	It("is not served unless enabled", func() {
+		brokerAPI = brokerapi.New(fakeServiceBroker, lagertest.NewTestLogger("broker-api"), credentials)
+		response := makePricingRequest("/v2/service_plans/" + planID + "/pricing")
+		Expect(response.Code).To(Equal(404))
+	})
+
+	It("returns the price calculated for the plan", func() {
+		response := makePricingRequest("/v2/service_plans/" + planID + "/pricing?quantity=2&duration=5")
+		Expect(response.Code).To(Equal(200))
+		Expect(response.Body.String()).To(MatchJSON(`{"amount":12.5,"currency":"USD","description":"2 instances for 5 hours"}`))
+
+		Expect(calculator.CalculatedPlanID).To(Equal(planID))
+		Expect(calculator.CalculatedQuantity).To(Equal(2))
+		Expect(calculator.CalculatedDuration).To(Equal(5 * time.Hour))
+	})
+
+	It("defaults to one instance for one hour", func() {
+		makePricingRequest("/v2/service_plans/" + planID + "/pricing")
+		Expect(calculator.CalculatedQuantity).To(Equal(1))
+		Expect(calculator.CalculatedDuration).To(Equal(time.Hour))
+	})
+
+	It("returns a 404 for an unknown plan", func() {
+		response := makePricingRequest("/v2/service_plans/unknown-plan/pricing")
+		Expect(response.Code).To(Equal(404))
+		Expect(response.Body.String()).To(MatchJSON(`{"description":"plan does not exist"}`))
+		Expect(calculator.CalculatedPlanID).To(BeEmpty())
+	})
+
+	It("returns a 400 for an invalid quantity", func() {
+		response := makePricingRequest("/v2/service_plans/" + planID + "/pricing?quantity=none")
+		Expect(response.Code).To(Equal(400))
+		Expect(response.Body.String()).To(MatchJSON(`{"description":"quantity must be a positive integer, got \"none\""}`))
+	})
+
+	It("returns a 400 for an invalid duration", func() {
+		response := makePricingRequest("/v2/service_plans/" + planID + "/pricing?duration=-1")
+		Expect(response.Code).To(Equal(400))
+	})
+
+	It("returns a 500 when the price cannot be calculated", func() {
+		calculator.CalculatePriceError = errors.New("pricing unavailable")
+		response := makePricingRequest("/v2/service_plans/" + planID + "/pricing")
+		Expect(response.Code).To(Equal(500))
+		Expect(response.Body.String()).To(MatchJSON(`{"description":"pricing unavailable"}`))
+	})
+})
@@ -0,0 +1,63 @@
+package brokerapi
+
+import (
+	"net/http"
+
+	"github.com/pivotal-golang/lager"
+)
+
+const catalogProbeLogKey = "catalog-probe"
+
+type ProbeCatalogResponse struct {
+	Services []ProbeService `json:"services"`
+}
+
+type ProbeService struct {
+	ID    string      `json:"id"`
+	Name  string      `json:"name"`
+	Plans []ProbePlan `json:"plans"`
+}
+
+type CatalogProber interface {
+	ProbeCatalog() []ProbeService
+}
+
+type ProbePlan struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func wrapCatalogProbe(handler http.Handler, prober CatalogProber, probeHeader string, logger lager.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != "GET" || req.URL.Path != "/v2/catalog" || req.Header.Get(probeHeader) == "" {
+			handler.ServeHTTP(w, req)
+			return
+		}
+
+		logger.Debug(catalogProbeLogKey, lager.Data{
+			"header": probeHeader,
+		})
+
+		respond(w, http.StatusOK, ProbeCatalogResponse{Services: prober.ProbeCatalog()})
+	})
+}
+
+func ProbeServices(services []Service) []ProbeService {
+	probeServices := []ProbeService{}
+	for _, service := range services {
+		probePlans := []ProbePlan{}
+		for _, plan := range service.Plans {
+			probePlans = append(probePlans, ProbePlan{
+				ID:   plan.ID,
+				Name: plan.Name,
+			})
+		}
+
+		probeServices = append(probeServices, ProbeService{
+			ID:    service.ID,
+			Name:  service.Name,
+			Plans: probePlans,
+		})
+	}
+	return probeServices
+}
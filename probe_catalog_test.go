@@ -0,0 +1,115 @@
+package brokerapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/brokerapi"
+	"github.com/pivotal-cf/brokerapi/fakes"
+)
+
+type probingBroker struct {
+	fakes.FakeServiceBroker
+}
+
+func (broker *probingBroker) ProbeCatalog() []brokerapi.ProbeService {
+	return []brokerapi.ProbeService{{
+		ID:    "service-id",
+		Name:  "service-name",
+		Plans: []brokerapi.ProbePlan{{ID: "plan-id", Name: "plan-name"}},
+	}}
+}
+
+var _ = Describe("Catalog probes", func() {
+	var serviceBroker *probingBroker
+	var brokerAPI http.Handler
+	var credentials = brokerapi.BrokerCredentials{
+		Username: "username",
+		Password: "password",
+	}
+
+	makeCatalogRequest := func(probe, authenticated bool) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("GET", "/v2/catalog", nil)
+		if probe {
+			request.Header.Set("X-Health-Probe", "true")
+		}
+		if authenticated {
+			request.SetBasicAuth(credentials.Username, credentials.Password)
+		}
+		brokerAPI.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	BeforeEach(func() {
+		brokerLogger := lagertest.NewTestLogger("broker-api")
+		serviceBroker = &probingBroker{}
+		brokerAPI = brokerapi.New(serviceBroker, brokerLogger, credentials, brokerapi.WithCatalogProbeHeader("X-Health-Probe"))
+	})
+
+	It("returns the broker's minimal catalog when the probe header is present", func() {
+		response := makeCatalogRequest(true, false)
+		Expect(response.Code).To(Equal(200))
+		Expect(response.Body.String()).To(MatchJSON(`{
+			"services": [{
+				"id": "service-id",
+				"name": "service-name",
+				"plans": [{
+					"id": "plan-id",
+					"name": "plan-name"
+				}]
+			}]
+		}`))
+	})
+
+	It("does not compute the full catalog for probes", func() {
+		makeCatalogRequest(true, false)
+		Expect(serviceBroker.BrokerCalled).To(BeFalse())
+	})
+
+	Context("when the broker can't be probed", func() {
+		var fakeServiceBroker *fakes.FakeServiceBroker
+
+		BeforeEach(func() {
+			fakeServiceBroker = &fakes.FakeServiceBroker{}
+			brokerAPI = brokerapi.New(fakeServiceBroker, lagertest.NewTestLogger("broker-api"), credentials, brokerapi.WithCatalogProbeHeader("X-Health-Probe"))
+		})
+
+		It("requires authentication for probes", func() {
+			response := makeCatalogRequest(true, false)
+			Expect(response.Code).To(Equal(401))
+			Expect(fakeServiceBroker.BrokerCalled).To(BeFalse())
+		})
+
+		It("returns the full catalog for authenticated probes", func() {
+			response := makeCatalogRequest(true, true)
+			Expect(response.Code).To(Equal(200))
+			Expect(response.Body.String()).To(ContainSubstring("Long description"))
+		})
+	})
+
+	It("builds probe services from a catalog", func() {
+		probeServices := brokerapi.ProbeServices((&fakes.FakeServiceBroker{}).Services())
+		Expect(probeServices).To(Equal([]brokerapi.ProbeService{{
+			ID:    "0A789746-596F-4CEA-BFAC-A0795DA056E3",
+			Name:  "p-cassandra",
+			Plans: []brokerapi.ProbePlan{{ID: "ABE176EE-F69F-4A96-80CE-142595CC24E3", Name: "default"}},
+		}}))
+	})
+
+	It("returns the full catalog without the probe header", func() {
+		response := makeCatalogRequest(false, true)
+		Expect(response.Code).To(Equal(200))
+		Expect(response.Body.String()).To(ContainSubstring("Long description"))
+	})
+
+	It("still requires authentication without the probe header", func() {
+		response := makeCatalogRequest(false, false)
+		Expect(response.Code).To(Equal(401))
+	})
+})
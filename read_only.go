@@ -0,0 +1,51 @@
+package brokerapi
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/pivotal-golang/lager"
+)
+
+const readOnlyErrorKey = "read-only"
+
+var errReadOnly = errors.New("broker is in read-only maintenance mode")
+
+type ReadOnlyMode struct {
+	mutex    sync.RWMutex
+	readOnly bool
+}
+
+func NewReadOnlyMode(readOnly bool) *ReadOnlyMode {
+	return &ReadOnlyMode{readOnly: readOnly}
+}
+
+func (mode *ReadOnlyMode) SetReadOnly(readOnly bool) {
+	mode.mutex.Lock()
+	defer mode.mutex.Unlock()
+
+	mode.readOnly = readOnly
+}
+
+func (mode *ReadOnlyMode) ReadOnly() bool {
+	mode.mutex.RLock()
+	defer mode.mutex.RUnlock()
+
+	return mode.readOnly
+}
+
+func rejectWhenReadOnly(handler http.HandlerFunc, mode *ReadOnlyMode, logger lager.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if mode.ReadOnly() {
+			logger.Error(readOnlyErrorKey, errReadOnly, lager.Data{
+				"method": req.Method,
+				"path":   req.URL.Path,
+			})
+			respondServiceUnavailable(w, errReadOnly)
+			return
+		}
+
+		handler(w, req)
+	}
+}
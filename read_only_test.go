@@ -0,0 +1,72 @@
+package brokerapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/brokerapi"
+	"github.com/pivotal-cf/brokerapi/fakes"
+)
+
+var _ = Describe("Read-only mode", func() {
+	var fakeServiceBroker *fakes.FakeServiceBroker
+	var readOnlyMode *brokerapi.ReadOnlyMode
+	var brokerAPI http.Handler
+	var credentials = brokerapi.BrokerCredentials{
+		Username: "username",
+		Password: "password",
+	}
+
+	makeRequest := func(method, path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(method, path, strings.NewReader(`{"plan_id":"plan-id"}`))
+		request.SetBasicAuth(credentials.Username, credentials.Password)
+		brokerAPI.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	BeforeEach(func() {
+		fakeServiceBroker = &fakes.FakeServiceBroker{
+			InstanceLimit: 3,
+		}
+		readOnlyMode = brokerapi.NewReadOnlyMode(true)
+		brokerAPI = brokerapi.New(fakeServiceBroker, lagertest.NewTestLogger("broker-api"), credentials, brokerapi.WithReadOnlyMode(readOnlyMode))
+	})
+
+	It("rejects mutating operations with a 503", func() {
+		instancePath := "/v2/service_instances/" + uniqueInstanceID()
+		bindingPath := instancePath + "/service_bindings/" + uniqueBindingID()
+
+		for _, request := range [][]string{
+			{"PUT", instancePath},
+			{"DELETE", instancePath},
+			{"PUT", bindingPath},
+			{"DELETE", bindingPath},
+		} {
+			response := makeRequest(request[0], request[1])
+			Expect(response.Code).To(Equal(503), request[0]+" "+request[1])
+			Expect(response.Body.String()).To(MatchJSON(`{"description":"broker is in read-only maintenance mode"}`))
+		}
+
+		Expect(fakeServiceBroker.ProvisionedInstanceIDs).To(BeEmpty())
+		Expect(fakeServiceBroker.BoundBindingIDs).To(BeEmpty())
+	})
+
+	It("continues to serve the catalog", func() {
+		Expect(makeRequest("GET", "/v2/catalog").Code).To(Equal(200))
+	})
+
+	It("can be toggled at runtime", func() {
+		readOnlyMode.SetReadOnly(false)
+		Expect(makeRequest("PUT", "/v2/service_instances/"+uniqueInstanceID()).Code).To(Equal(201))
+
+		readOnlyMode.SetReadOnly(true)
+		Expect(makeRequest("PUT", "/v2/service_instances/"+uniqueInstanceID()).Code).To(Equal(503))
+	})
+})
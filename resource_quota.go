@@ -0,0 +1,17 @@
+package brokerapi
+
+import "fmt"
+
+type ResourceChecker interface {
+	CheckQuota(organizationGUID, planID string, quotas map[string]int) error
+}
+
+type ResourceQuotaExceededError struct {
+	Resource  string
+	Quota     int
+	Requested int
+}
+
+func (err ResourceQuotaExceededError) Error() string {
+	return fmt.Sprintf("resource quota exceeded for %s: requested %d, quota %d", err.Resource, err.Requested, err.Quota)
+}
@@ -3,6 +3,7 @@ package brokerapi
 type EmptyResponse struct{}
 
 type ErrorResponse struct {
+	Error       string `json:"error,omitempty"`
 	Description string `json:"description"`
 }
 
@@ -11,9 +12,41 @@ type CatalogResponse struct {
 }
 
 type ProvisioningResponse struct {
-	DashboardURL string `json:"dashboard_url,omitempty"`
+	DashboardURL string                 `json:"dashboard_url,omitempty"`
+	Parameters   map[string]interface{} `json:"parameters,omitempty"`
 }
 
 type BindingResponse struct {
 	Credentials interface{} `json:"credentials"`
 }
+
+type ServicePlanListResponse struct {
+	Plans        []ServicePlan `json:"plans"`
+	TotalResults int           `json:"total_results"`
+	TotalPages   int           `json:"total_pages"`
+}
+
+func newServicePlanListResponse(plans []ServicePlan, offset, limit int) ServicePlanListResponse {
+	totalResults := len(plans)
+
+	totalPages := 1
+	if totalResults == 0 {
+		totalPages = 0
+	} else if limit > 0 {
+		totalPages = (totalResults + limit - 1) / limit
+	}
+
+	if offset > totalResults {
+		offset = totalResults
+	}
+	plans = plans[offset:]
+	if limit > 0 && limit < len(plans) {
+		plans = plans[:limit]
+	}
+
+	return ServicePlanListResponse{
+		Plans:        plans,
+		TotalResults: totalResults,
+		TotalPages:   totalPages,
+	}
+}
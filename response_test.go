@@ -66,5 +66,30 @@ var _ = Describe("Error Response", func() {
 
 			Expect(errorResponse).To(MarshalToJSON(json))
 		})
+
+		It("has an error code field when set", func() {
+			errorResponse := brokerapi.ErrorResponse{
+				Error:       brokerapi.ErrorCodeConcurrencyError,
+				Description: "a bad thing happened",
+			}
+			json := `{"error":"ConcurrencyError","description":"a bad thing happened"}`
+
+			Expect(errorResponse).To(MarshalToJSON(json))
+		})
+	})
+})
+
+var _ = Describe("Service Plan List Response", func() {
+	Describe("JSON encoding", func() {
+		It("has a list of plans and pagination fields", func() {
+			servicePlanListResponse := brokerapi.ServicePlanListResponse{
+				Plans:        []brokerapi.ServicePlan{},
+				TotalResults: 0,
+				TotalPages:   0,
+			}
+			json := `{"plans":[],"total_results":0,"total_pages":0}`
+
+			Expect(servicePlanListResponse).To(MarshalToJSON(json))
+		})
 	})
 })
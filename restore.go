@@ -0,0 +1,85 @@
+package brokerapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/pivotal-golang/lager"
+)
+
+const restoreLogKey = "restore"
+const backupIDLogKey = "backup-id"
+const invalidRestoreDetailsErrorKey = "invalid-restore-details"
+const restoreNotSupportedErrorKey = "restore-not-supported"
+
+var errRestoreNotSupported = errors.New("this broker does not support restoring instances")
+var errBackupIDMissing = errors.New("backup_id is required")
+
+type RestorableServiceBroker interface {
+	RestoreInstance(instanceID, backupID string) error
+}
+
+type RestoreDetails struct {
+	BackupID string `json:"backup_id"`
+}
+
+func restore(restorableBroker RestorableServiceBroker, router httpRouter, logger lager.Logger, options options) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		instanceID := router.Vars(req)["instance_id"]
+
+		logger := logger.Session(restoreLogKey, lager.Data{
+			instanceIDLogKey: instanceID,
+		})
+
+		if restorableBroker == nil {
+			logger.Error(restoreNotSupportedErrorKey, errRestoreNotSupported)
+			respond(w, http.StatusMethodNotAllowed, ErrorResponse{
+				Description: errRestoreNotSupported.Error(),
+			})
+			return
+		}
+
+		var restoreDetails RestoreDetails
+		if err := json.NewDecoder(req.Body).Decode(&restoreDetails); err != nil {
+			logger.Error(invalidRestoreDetailsErrorKey, err)
+			respond(w, statusUnprocessableEntity, ErrorResponse{
+				Description: err.Error(),
+			})
+			return
+		}
+
+		if restoreDetails.BackupID == "" {
+			logger.Error(invalidRestoreDetailsErrorKey, errBackupIDMissing)
+			respond(w, http.StatusBadRequest, ErrorResponse{
+				Description: errBackupIDMissing.Error(),
+			})
+			return
+		}
+
+		logger = logger.WithData(lager.Data{
+			backupIDLogKey: restoreDetails.BackupID,
+		})
+
+		if err := restorableBroker.RestoreInstance(instanceID, restoreDetails.BackupID); err != nil {
+			switch err {
+			case ErrInstanceDoesNotExist:
+				logBrokerError(logger, options.errorLogLevels, instanceMissingErrorKey, err)
+				respond(w, http.StatusNotFound, ErrorResponse{
+					Description: err.Error(),
+				})
+			case ErrServiceUnavailable:
+				logBrokerError(logger, options.errorLogLevels, serviceUnavailableErrorKey, err)
+				respondServiceUnavailable(w, err)
+			default:
+				logBrokerError(logger, options.errorLogLevels, unknownErrorKey, err)
+				respond(w, http.StatusInternalServerError, ErrorResponse{
+					Description: err.Error(),
+				})
+			}
+			return
+		}
+
+		respond(w, http.StatusOK, EmptyResponse{})
+	}
+}
@@ -0,0 +1,76 @@
+package brokerapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/brokerapi"
+	"github.com/pivotal-cf/brokerapi/fakes"
+)
+
+var _ = Describe("Restore endpoint", func() {
+	var fakeServiceBroker *fakes.FakeRestorableServiceBroker
+	var brokerAPI http.Handler
+	var instanceID string
+	var credentials = brokerapi.BrokerCredentials{
+		Username: "username",
+		Password: "password",
+	}
+
+	makeRestoreRequest := func(body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("POST", "/v2/service_instances/"+instanceID+"/restore", strings.NewReader(body))
+		request.SetBasicAuth(credentials.Username, credentials.Password)
+		brokerAPI.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	BeforeEach(func() {
+		instanceID = uniqueInstanceID()
+		fakeServiceBroker = &fakes.FakeRestorableServiceBroker{}
+		brokerAPI = brokerapi.New(fakeServiceBroker, lagertest.NewTestLogger("broker-api"), credentials, brokerapi.WithRestoreSupport())
+	})
+
+	It("is not served unless enabled", func() {
+		brokerAPI = brokerapi.New(fakeServiceBroker, lagertest.NewTestLogger("broker-api"), credentials)
+		response := makeRestoreRequest(`{"backup_id":"backup-id"}`)
+		Expect(response.Code).To(Equal(404))
+	})
+
+	It("returns a 405 when the broker cannot restore instances", func() {
+		brokerAPI = brokerapi.New(&fakes.FakeServiceBroker{}, lagertest.NewTestLogger("broker-api"), credentials, brokerapi.WithRestoreSupport())
+		response := makeRestoreRequest(`{"backup_id":"backup-id"}`)
+		Expect(response.Code).To(Equal(405))
+		Expect(response.Body.String()).To(MatchJSON(`{"description":"this broker does not support restoring instances"}`))
+	})
+
+	It("calls RestoreInstance with the instance and backup IDs", func() {
+		makeRestoreRequest(`{"backup_id":"backup-id"}`)
+		Expect(fakeServiceBroker.RestoredInstanceIDs).To(Equal([]string{instanceID}))
+		Expect(fakeServiceBroker.RestoredBackupIDs).To(Equal([]string{"backup-id"}))
+	})
+
+	It("returns a 200 once the instance has been restored", func() {
+		response := makeRestoreRequest(`{"backup_id":"backup-id"}`)
+		Expect(response.Code).To(Equal(200))
+		Expect(response.Body.String()).To(MatchJSON(`{}`))
+	})
+
+	It("returns a 400 when no backup ID is given", func() {
+		response := makeRestoreRequest(`{}`)
+		Expect(response.Code).To(Equal(400))
+		Expect(fakeServiceBroker.BrokerCalled).To(BeFalse())
+	})
+
+	It("returns a 404 when the instance does not exist", func() {
+		fakeServiceBroker.RestoreError = brokerapi.ErrInstanceDoesNotExist
+		response := makeRestoreRequest(`{"backup_id":"backup-id"}`)
+		Expect(response.Code).To(Equal(404))
+	})
+})
@@ -0,0 +1,78 @@
+package retry
+
+import (
+	"time"
+
+	"github.com/pivotal-cf/brokerapi"
+)
+
+type RetryingServiceBroker struct {
+	broker      brokerapi.ServiceBroker
+	maxAttempts int
+	delay       time.Duration
+}
+
+func NewRetryingServiceBroker(broker brokerapi.ServiceBroker, maxAttempts int, delay time.Duration) *RetryingServiceBroker {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	return &RetryingServiceBroker{
+		broker:      broker,
+		maxAttempts: maxAttempts,
+		delay:       delay,
+	}
+}
+
+func WithTransientErrorRetry(maxAttempts int, delay time.Duration) brokerapi.Option {
+	return brokerapi.WithServiceBrokerWrapper(func(broker brokerapi.ServiceBroker) brokerapi.ServiceBroker {
+		return NewRetryingServiceBroker(broker, maxAttempts, delay)
+	})
+}
+
+func (broker *RetryingServiceBroker) Services() []brokerapi.Service {
+	return broker.broker.Services()
+}
+
+func (broker *RetryingServiceBroker) Provision(instanceID string, serviceDetails brokerapi.ServiceDetails) error {
+	return broker.retry(func() error {
+		return broker.broker.Provision(instanceID, serviceDetails)
+	})
+}
+
+func (broker *RetryingServiceBroker) Deprovision(instanceID string) error {
+	return broker.retry(func() error {
+		return broker.broker.Deprovision(instanceID)
+	})
+}
+
+func (broker *RetryingServiceBroker) Bind(instanceID, bindingID string) (interface{}, error) {
+	var credentials interface{}
+	err := broker.retry(func() error {
+		var err error
+		credentials, err = broker.broker.Bind(instanceID, bindingID)
+		return err
+	})
+	return credentials, err
+}
+
+func (broker *RetryingServiceBroker) Unbind(instanceID, bindingID string) error {
+	return broker.retry(func() error {
+		return broker.broker.Unbind(instanceID, bindingID)
+	})
+}
+
+func (broker *RetryingServiceBroker) retry(operation func() error) error {
+	var err error
+	for attempt := 1; attempt <= broker.maxAttempts; attempt++ {
+		err = operation()
+		if err != brokerapi.ErrTransient {
+			return err
+		}
+
+		if attempt < broker.maxAttempts {
+			time.Sleep(broker.delay)
+		}
+	}
+	return err
+}
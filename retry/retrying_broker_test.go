@@ -0,0 +1,127 @@
+package retry_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/brokerapi"
+	"github.com/pivotal-cf/brokerapi/fakes"
+	"github.com/pivotal-cf/brokerapi/retry"
+)
+
+type flakyBroker struct {
+	fakes.FakeServiceBroker
+
+	failures int
+	err      error
+	attempts []time.Time
+}
+
+func (broker *flakyBroker) attempt() error {
+	broker.attempts = append(broker.attempts, time.Now())
+	if len(broker.attempts) <= broker.failures {
+		return broker.err
+	}
+	return nil
+}
+
+func (broker *flakyBroker) Provision(instanceID string, serviceDetails brokerapi.ServiceDetails) error {
+	if err := broker.attempt(); err != nil {
+		return err
+	}
+	return broker.FakeServiceBroker.Provision(instanceID, serviceDetails)
+}
+
+func (broker *flakyBroker) Bind(instanceID, bindingID string) (interface{}, error) {
+	if err := broker.attempt(); err != nil {
+		return nil, err
+	}
+	return broker.FakeServiceBroker.Bind(instanceID, bindingID)
+}
+
+var _ = Describe("RetryingServiceBroker", func() {
+	var innerBroker *flakyBroker
+	var broker *retry.RetryingServiceBroker
+
+	BeforeEach(func() {
+		innerBroker = &flakyBroker{err: brokerapi.ErrTransient}
+		innerBroker.InstanceLimit = 3
+		broker = retry.NewRetryingServiceBroker(innerBroker, 3, 10*time.Millisecond)
+	})
+
+	It("does not retry successful operations", func() {
+		err := broker.Provision("instance-id", brokerapi.ServiceDetails{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(innerBroker.attempts).To(HaveLen(1))
+	})
+
+	It("retries transient errors until the operation succeeds", func() {
+		innerBroker.failures = 2
+
+		err := broker.Provision("instance-id", brokerapi.ServiceDetails{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(innerBroker.attempts).To(HaveLen(3))
+		Expect(innerBroker.ProvisionedInstanceIDs).To(Equal([]string{"instance-id"}))
+	})
+
+	It("returns the credentials from a retried bind", func() {
+		innerBroker.failures = 1
+		innerBroker.Credentials = "credentials"
+
+		credentials, err := broker.Bind("instance-id", "binding-id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(credentials).To(Equal("credentials"))
+		Expect(innerBroker.attempts).To(HaveLen(2))
+	})
+
+	It("gives up after the maximum number of attempts", func() {
+		innerBroker.failures = 5
+
+		err := broker.Provision("instance-id", brokerapi.ServiceDetails{})
+		Expect(err).To(Equal(brokerapi.ErrTransient))
+		Expect(innerBroker.attempts).To(HaveLen(3))
+	})
+
+	It("waits between attempts", func() {
+		innerBroker.failures = 2
+
+		broker.Provision("instance-id", brokerapi.ServiceDetails{})
+		Expect(innerBroker.attempts).To(HaveLen(3))
+		for i := 1; i < len(innerBroker.attempts); i++ {
+			Expect(innerBroker.attempts[i].Sub(innerBroker.attempts[i-1])).To(BeNumerically(">=", 10*time.Millisecond))
+		}
+	})
+
+	It("does not retry other errors", func() {
+		innerBroker.failures = 2
+		innerBroker.err = errors.New("boom")
+
+		err := broker.Provision("instance-id", brokerapi.ServiceDetails{})
+		Expect(err).To(MatchError("boom"))
+		Expect(innerBroker.attempts).To(HaveLen(1))
+	})
+
+	Describe("WithTransientErrorRetry", func() {
+		It("retries operations made through the API", func() {
+			innerBroker.failures = 2
+			credentials := brokerapi.BrokerCredentials{Username: "username", Password: "password"}
+			brokerAPI := brokerapi.New(innerBroker, lagertest.NewTestLogger("broker-api"), credentials, retry.WithTransientErrorRetry(3, time.Millisecond))
+
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("PUT", "/v2/service_instances/instance-id", strings.NewReader(`{"service_id":"service-id","plan_id":"plan-id"}`))
+			request.SetBasicAuth(credentials.Username, credentials.Password)
+			brokerAPI.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(201))
+			Expect(innerBroker.attempts).To(HaveLen(3))
+		})
+	})
+})
@@ -12,17 +12,52 @@ type ServiceBroker interface {
 	Unbind(instanceID, bindingID string) error
 }
 
+type CredentialsFunc func() (interface{}, error)
+
 type ServiceDetails struct {
-	ID               string `json:"service_id"`
-	PlanID           string `json:"plan_id"`
-	OrganizationGUID string `json:"organization_guid"`
-	SpaceGUID        string `json:"space_guid"`
+	ID               string                 `json:"service_id"`
+	PlanID           string                 `json:"plan_id"`
+	OrganizationGUID string                 `json:"organization_guid"`
+	SpaceGUID        string                 `json:"space_guid"`
+	Parameters       map[string]interface{} `json:"parameters,omitempty"`
+	Context          map[string]interface{} `json:"context,omitempty"`
+}
+
+type BindDetails struct {
+	ServiceID    string                 `json:"service_id"`
+	PlanID       string                 `json:"plan_id"`
+	AppGUID      string                 `json:"app_guid"`
+	BindResource *BindResource          `json:"bind_resource,omitempty"`
+	BindingTTL   *int                   `json:"binding_ttl,omitempty"`
+	Context      map[string]interface{} `json:"context,omitempty"`
+}
+
+type BindResource struct {
+	AppGUID string `json:"app_guid,omitempty"`
+	Route   string `json:"route,omitempty"`
 }
 
 var (
 	ErrInstanceAlreadyExists = errors.New("instance already exists")
 	ErrInstanceDoesNotExist  = errors.New("instance does not exist")
 	ErrInstanceLimitMet      = errors.New("instance limit for this service has been reached")
+	ErrQuotaExceeded         = errors.New("quota has been exceeded")
 	ErrBindingAlreadyExists  = errors.New("binding already exists")
 	ErrBindingDoesNotExist   = errors.New("binding does not exist")
+	ErrServiceUnavailable    = errors.New("service is temporarily unavailable, try again later")
+	ErrConcurrentOperation   = errors.New("another operation for this service instance is in progress")
+	ErrTransient             = errors.New("a transient error occurred, the operation can be retried")
 )
+
+var ErrInstanceGone error = InstanceGoneError{}
+
+type InstanceGoneError struct {
+	Description string
+}
+
+func (err InstanceGoneError) Error() string {
+	if err.Description == "" {
+		return "instance has already been deprovisioned"
+	}
+	return err.Description
+}
@@ -0,0 +1,168 @@
+package simulator
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/pivotal-cf/brokerapi"
+)
+
+const (
+	ServiceID = "5b2d2a55-8a6d-4c1b-9a2f-6f5a0c3b1e01"
+	PlanID    = "a0e7e3e2-3b0f-4d5c-8f59-1d9a2c4b7e02"
+)
+
+type SimConfig struct {
+	ProvisionLatencyMean   time.Duration
+	ProvisionLatencyStdDev time.Duration
+	ErrorRatePercent       float64
+	InstanceLimit          int
+	Seed                   int64
+	Sleep                  func(time.Duration)
+}
+
+type simulatedBroker struct {
+	config SimConfig
+
+	mutex     sync.Mutex
+	random    *rand.Rand
+	instances map[string]map[string]bool
+}
+
+func NewSimulatedBroker(config SimConfig) brokerapi.ServiceBroker {
+	seed := config.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	if config.Sleep == nil {
+		config.Sleep = time.Sleep
+	}
+
+	return &simulatedBroker{
+		config:    config,
+		random:    rand.New(rand.NewSource(seed)),
+		instances: map[string]map[string]bool{},
+	}
+}
+
+func (broker *simulatedBroker) Services() []brokerapi.Service {
+	return []brokerapi.Service{
+		{
+			ID:          ServiceID,
+			Name:        "simulated-service",
+			Description: "A simulated service for load testing",
+			Bindable:    true,
+			Plans: []brokerapi.ServicePlan{
+				{
+					ID:          PlanID,
+					Name:        "simulated-plan",
+					Description: "A simulated plan for load testing",
+				},
+			},
+		},
+	}
+}
+
+func (broker *simulatedBroker) Provision(instanceID string, serviceDetails brokerapi.ServiceDetails) error {
+	broker.config.Sleep(broker.provisionLatency())
+
+	if broker.fail() {
+		return brokerapi.ErrServiceUnavailable
+	}
+
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+
+	if _, ok := broker.instances[instanceID]; ok {
+		return brokerapi.ErrInstanceAlreadyExists
+	}
+
+	if broker.config.InstanceLimit > 0 && len(broker.instances) >= broker.config.InstanceLimit {
+		return brokerapi.ErrInstanceLimitMet
+	}
+
+	broker.instances[instanceID] = map[string]bool{}
+	return nil
+}
+
+func (broker *simulatedBroker) Deprovision(instanceID string) error {
+	if broker.fail() {
+		return brokerapi.ErrServiceUnavailable
+	}
+
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+
+	if _, ok := broker.instances[instanceID]; !ok {
+		return brokerapi.ErrInstanceDoesNotExist
+	}
+
+	delete(broker.instances, instanceID)
+	return nil
+}
+
+func (broker *simulatedBroker) Bind(instanceID, bindingID string) (interface{}, error) {
+	if broker.fail() {
+		return nil, brokerapi.ErrServiceUnavailable
+	}
+
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+
+	bindings, ok := broker.instances[instanceID]
+	if !ok {
+		return nil, brokerapi.ErrInstanceDoesNotExist
+	}
+
+	if bindings[bindingID] {
+		return nil, brokerapi.ErrBindingAlreadyExists
+	}
+
+	bindings[bindingID] = true
+	return map[string]string{
+		"instance_id": instanceID,
+		"binding_id":  bindingID,
+	}, nil
+}
+
+func (broker *simulatedBroker) Unbind(instanceID, bindingID string) error {
+	if broker.fail() {
+		return brokerapi.ErrServiceUnavailable
+	}
+
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+
+	bindings, ok := broker.instances[instanceID]
+	if !ok {
+		return brokerapi.ErrInstanceDoesNotExist
+	}
+
+	if !bindings[bindingID] {
+		return brokerapi.ErrBindingDoesNotExist
+	}
+
+	delete(bindings, bindingID)
+	return nil
+}
+
+func (broker *simulatedBroker) provisionLatency() time.Duration {
+	broker.mutex.Lock()
+	sample := broker.random.NormFloat64()
+	broker.mutex.Unlock()
+
+	latency := broker.config.ProvisionLatencyMean + time.Duration(sample*float64(broker.config.ProvisionLatencyStdDev))
+	if latency < 0 {
+		return 0
+	}
+	return latency
+}
+
+func (broker *simulatedBroker) fail() bool {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+
+	return broker.random.Float64()*100 < broker.config.ErrorRatePercent
+}
@@ -0,0 +1,128 @@
+package simulator_test
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/brokerapi"
+	"github.com/pivotal-cf/brokerapi/simulator"
+)
+
+var _ = Describe("Simulated broker", func() {
+	It("fails at the configured error rate", func() {
+		broker := simulator.NewSimulatedBroker(simulator.SimConfig{
+			ErrorRatePercent: 20,
+			Seed:             1,
+		})
+
+		requests := 1000
+		failures := 0
+		for i := 0; i < requests; i++ {
+			err := broker.Provision(fmt.Sprintf("instance-%d", i), brokerapi.ServiceDetails{})
+			if err != nil {
+				Expect(err).To(Equal(brokerapi.ErrServiceUnavailable))
+				failures++
+			}
+		}
+
+		rate := 0.2
+		stddev := math.Sqrt(rate * (1 - rate) / float64(requests))
+		Expect(float64(failures) / float64(requests)).To(BeNumerically("~", rate, stddev))
+	})
+
+	It("provisions with the configured latency", func() {
+		mean := 4 * time.Millisecond
+		stddev := 2 * time.Millisecond
+		var latencies []time.Duration
+		broker := simulator.NewSimulatedBroker(simulator.SimConfig{
+			ProvisionLatencyMean:   mean,
+			ProvisionLatencyStdDev: stddev,
+			Seed:                   1,
+			Sleep: func(latency time.Duration) {
+				latencies = append(latencies, latency)
+			},
+		})
+
+		requests := 1000
+		for i := 0; i < requests; i++ {
+			err := broker.Provision(fmt.Sprintf("instance-%d", i), brokerapi.ServiceDetails{})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		Expect(latencies).To(HaveLen(requests))
+		var total time.Duration
+		for _, latency := range latencies {
+			Expect(latency).To(BeNumerically(">=", 0))
+			total += latency
+		}
+		Expect(total / time.Duration(requests)).To(BeNumerically("~", mean, stddev/10))
+	})
+
+	It("never sleeps for a negative latency", func() {
+		var latencies []time.Duration
+		broker := simulator.NewSimulatedBroker(simulator.SimConfig{
+			ProvisionLatencyStdDev: time.Second,
+			Seed:                   1,
+			Sleep: func(latency time.Duration) {
+				latencies = append(latencies, latency)
+			},
+		})
+
+		for i := 0; i < 100; i++ {
+			broker.Provision(fmt.Sprintf("instance-%d", i), brokerapi.ServiceDetails{})
+		}
+
+		Expect(latencies).To(ContainElement(time.Duration(0)))
+		for _, latency := range latencies {
+			Expect(latency).To(BeNumerically(">=", 0))
+		}
+	})
+
+	It("enforces the instance limit", func() {
+		broker := simulator.NewSimulatedBroker(simulator.SimConfig{InstanceLimit: 2})
+
+		Expect(broker.Provision("instance-1", brokerapi.ServiceDetails{})).To(BeNil())
+		Expect(broker.Provision("instance-2", brokerapi.ServiceDetails{})).To(BeNil())
+		Expect(broker.Provision("instance-3", brokerapi.ServiceDetails{})).To(Equal(brokerapi.ErrInstanceLimitMet))
+	})
+
+	It("tracks instances and bindings", func() {
+		broker := simulator.NewSimulatedBroker(simulator.SimConfig{})
+
+		Expect(broker.Provision("instance-id", brokerapi.ServiceDetails{})).To(BeNil())
+		Expect(broker.Provision("instance-id", brokerapi.ServiceDetails{})).To(Equal(brokerapi.ErrInstanceAlreadyExists))
+
+		_, err := broker.Bind("instance-id", "binding-id")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = broker.Bind("instance-id", "binding-id")
+		Expect(err).To(Equal(brokerapi.ErrBindingAlreadyExists))
+
+		Expect(broker.Unbind("instance-id", "binding-id")).To(BeNil())
+		Expect(broker.Unbind("instance-id", "binding-id")).To(Equal(brokerapi.ErrBindingDoesNotExist))
+
+		Expect(broker.Deprovision("instance-id")).To(BeNil())
+		Expect(broker.Deprovision("instance-id")).To(Equal(brokerapi.ErrInstanceDoesNotExist))
+	})
+
+	It("serves the API", func() {
+		credentials := brokerapi.BrokerCredentials{Username: "username", Password: "password"}
+		brokerAPI := brokerapi.New(simulator.NewSimulatedBroker(simulator.SimConfig{}), lagertest.NewTestLogger("broker-api"), credentials)
+
+		recorder := httptest.NewRecorder()
+		body := `{"service_id":"` + simulator.ServiceID + `","plan_id":"` + simulator.PlanID + `"}`
+		request, _ := http.NewRequest("PUT", "/v2/service_instances/instance-id", strings.NewReader(body))
+		request.SetBasicAuth(credentials.Username, credentials.Password)
+		brokerAPI.ServeHTTP(recorder, request)
+
+		Expect(recorder.Code).To(Equal(201))
+	})
+})
@@ -0,0 +1,64 @@
+package brokerapi
+
+import "strings"
+
+var slugTransliterations = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ă': "a", 'ą': "a",
+	'æ': "ae",
+	'ç': "c", 'ć': "c", 'ĉ': "c", 'ċ': "c", 'č': "c",
+	'ď': "d", 'đ': "d", 'ð': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ĕ': "e", 'ė': "e", 'ę': "e", 'ě': "e",
+	'ĝ': "g", 'ğ': "g", 'ġ': "g", 'ģ': "g",
+	'ĥ': "h", 'ħ': "h",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ĩ': "i", 'ī': "i", 'ĭ': "i", 'į': "i", 'ı': "i",
+	'ĵ': "j",
+	'ķ': "k",
+	'ĺ': "l", 'ļ': "l", 'ľ': "l", 'ŀ': "l", 'ł': "l",
+	'ñ': "n", 'ń': "n", 'ņ': "n", 'ň': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ŏ': "o", 'ő': "o",
+	'œ': "oe",
+	'ŕ': "r", 'ŗ': "r", 'ř': "r",
+	'ś': "s", 'ŝ': "s", 'ş': "s", 'š': "s",
+	'ß': "ss",
+	'ţ': "t", 'ť': "t", 'ŧ': "t",
+	'þ': "th",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ũ': "u", 'ū': "u", 'ŭ': "u", 'ů': "u", 'ű': "u", 'ų': "u",
+	'ŵ': "w",
+	'ý': "y", 'ÿ': "y", 'ŷ': "y",
+	'ź': "z", 'ż': "z", 'ž': "z",
+}
+
+func Slugify(name string) string {
+	var slug []byte
+	separate := false
+
+	for _, r := range strings.ToLower(name) {
+		ascii, ok := slugTransliterations[r]
+		if !ok && (r >= 'a' && r <= 'z' || r >= '0' && r <= '9') {
+			ascii, ok = string(r), true
+		}
+
+		if !ok {
+			separate = true
+			continue
+		}
+
+		if separate && len(slug) > 0 {
+			slug = append(slug, '-')
+		}
+		slug = append(slug, ascii...)
+		separate = false
+	}
+
+	return string(slug)
+}
+
+func InstanceNameSlug(context map[string]interface{}) (string, bool) {
+	name, ok := context["instance_name"].(string)
+	if !ok {
+		return "", false
+	}
+
+	slug := Slugify(name)
+	return slug, slug != ""
+}
@@ -0,0 +1,63 @@
+package brokerapi_test
+
+import (
+	"net/url"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/brokerapi"
+)
+
+var _ = Describe("Slugs", func() {
+	Describe("Slugify", func() {
+		It("lowercases names and joins words with dashes", func() {
+			Expect(brokerapi.Slugify("My Production  DB")).To(Equal("my-production-db"))
+		})
+
+		It("trims leading and trailing separators", func() {
+			Expect(brokerapi.Slugify("  --orders db--  ")).To(Equal("orders-db"))
+		})
+
+		It("replaces reserved URL characters", func() {
+			slug := brokerapi.Slugify("a/b?c#d&e=f%g:h@i")
+			Expect(slug).To(Equal("a-b-c-d-e-f-g-h-i"))
+			Expect(url.QueryEscape(slug)).To(Equal(slug))
+		})
+
+		It("transliterates accented Latin letters to ASCII", func() {
+			Expect(brokerapi.Slugify("Café Données")).To(Equal("cafe-donnees"))
+			Expect(brokerapi.Slugify("Straße Œuvre №2")).To(Equal("strasse-oeuvre-2"))
+		})
+
+		It("drops other non-ASCII characters", func() {
+			Expect(brokerapi.Slugify("数据库 1")).To(Equal("1"))
+			Expect(brokerapi.Slugify("db数据prod")).To(Equal("db-prod"))
+		})
+
+		It("drops symbols and emoji", func() {
+			Expect(brokerapi.Slugify("db ✨ prod")).To(Equal("db-prod"))
+		})
+	})
+
+	Describe("InstanceNameSlug", func() {
+		It("slugifies context.instance_name", func() {
+			slug, ok := brokerapi.InstanceNameSlug(map[string]interface{}{
+				"platform":      "cloudfoundry",
+				"instance_name": "Orders DB",
+			})
+			Expect(ok).To(BeTrue())
+			Expect(slug).To(Equal("orders-db"))
+		})
+
+		It("reports a missing instance name", func() {
+			_, ok := brokerapi.InstanceNameSlug(map[string]interface{}{"platform": "cloudfoundry"})
+			Expect(ok).To(BeFalse())
+		})
+
+		It("reports a name with nothing usable in it", func() {
+			_, ok := brokerapi.InstanceNameSlug(map[string]interface{}{"instance_name": "!!!"})
+			Expect(ok).To(BeFalse())
+		})
+	})
+})
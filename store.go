@@ -0,0 +1,168 @@
+package brokerapi
+
+import "sync"
+
+type Store interface {
+	CreateInstance(instanceID string, serviceDetails ServiceDetails) error
+	FindInstance(instanceID string) (ServiceDetails, error)
+	DeleteInstance(instanceID string) error
+
+	CreateBinding(instanceID, bindingID string) error
+	DeleteBinding(instanceID, bindingID string) error
+}
+
+type memoryStore struct {
+	mutex        sync.Mutex
+	instances    map[string]*storedInstance
+	reservations map[string]string
+}
+
+type storedInstance struct {
+	serviceDetails ServiceDetails
+	bindingIDs     map[string]bool
+}
+
+func NewMemoryStore() Store {
+	return &memoryStore{
+		instances:    map[string]*storedInstance{},
+		reservations: map[string]string{},
+	}
+}
+
+func (store *memoryStore) CreateInstance(instanceID string, serviceDetails ServiceDetails) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if _, ok := store.instances[instanceID]; ok {
+		return ErrInstanceAlreadyExists
+	}
+
+	store.instances[instanceID] = &storedInstance{
+		serviceDetails: serviceDetails,
+		bindingIDs:     map[string]bool{},
+	}
+	return nil
+}
+
+func (store *memoryStore) FindInstance(instanceID string) (ServiceDetails, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	instance, ok := store.instances[instanceID]
+	if !ok {
+		return ServiceDetails{}, ErrInstanceDoesNotExist
+	}
+	return instance.serviceDetails, nil
+}
+
+func (store *memoryStore) DeleteInstance(instanceID string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if _, ok := store.instances[instanceID]; !ok {
+		return ErrInstanceDoesNotExist
+	}
+
+	delete(store.instances, instanceID)
+	return nil
+}
+
+func (store *memoryStore) CreateBinding(instanceID, bindingID string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	instance, ok := store.instances[instanceID]
+	if !ok {
+		return ErrInstanceDoesNotExist
+	}
+
+	if instance.bindingIDs[bindingID] {
+		return ErrBindingAlreadyExists
+	}
+
+	instance.bindingIDs[bindingID] = true
+	return nil
+}
+
+func (store *memoryStore) DeleteBinding(instanceID, bindingID string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	instance, ok := store.instances[instanceID]
+	if !ok {
+		return ErrInstanceDoesNotExist
+	}
+
+	if !instance.bindingIDs[bindingID] {
+		return ErrBindingDoesNotExist
+	}
+
+	delete(instance.bindingIDs, bindingID)
+	return nil
+}
+
+type storeBroker struct {
+	ServiceBroker
+	store Store
+}
+
+func newStoreBroker(serviceBroker ServiceBroker, store Store) ServiceBroker {
+	return storeBroker{
+		ServiceBroker: serviceBroker,
+		store:         store,
+	}
+}
+
+func (broker storeBroker) Provision(instanceID string, serviceDetails ServiceDetails) error {
+	if err := broker.store.CreateInstance(instanceID, serviceDetails); err != nil {
+		return err
+	}
+
+	if err := broker.ServiceBroker.Provision(instanceID, serviceDetails); err != nil {
+		broker.store.DeleteInstance(instanceID)
+		return err
+	}
+
+	return nil
+}
+
+func (broker storeBroker) Deprovision(instanceID string) error {
+	if _, err := broker.store.FindInstance(instanceID); err != nil {
+		return err
+	}
+
+	err := broker.ServiceBroker.Deprovision(instanceID)
+	_, gone := err.(InstanceGoneError)
+	if err == nil || err == ErrInstanceDoesNotExist || gone {
+		broker.store.DeleteInstance(instanceID)
+	}
+
+	return err
+}
+
+func (broker storeBroker) Bind(instanceID, bindingID string) (interface{}, error) {
+	if err := broker.store.CreateBinding(instanceID, bindingID); err != nil {
+		return nil, err
+	}
+
+	credentials, err := broker.ServiceBroker.Bind(instanceID, bindingID)
+	if err != nil {
+		broker.store.DeleteBinding(instanceID, bindingID)
+		return nil, err
+	}
+
+	return credentials, nil
+}
+
+func (broker storeBroker) Unbind(instanceID, bindingID string) error {
+	if err := broker.store.DeleteBinding(instanceID, bindingID); err != nil {
+		return err
+	}
+
+	if err := broker.ServiceBroker.Unbind(instanceID, bindingID); err != nil {
+		broker.store.CreateBinding(instanceID, bindingID)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package brokerapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/brokerapi"
+	"github.com/pivotal-cf/brokerapi/fakes"
+)
+
+var _ = Describe("Memory Store", func() {
+	var store brokerapi.Store
+	var serviceDetails brokerapi.ServiceDetails
+
+	BeforeEach(func() {
+		store = brokerapi.NewMemoryStore()
+		serviceDetails = brokerapi.ServiceDetails{
+			ID:     "service-id",
+			PlanID: "plan-id",
+		}
+	})
+
+	Describe("instances", func() {
+		It("finds a created instance", func() {
+			err := store.CreateInstance("instance-id", serviceDetails)
+			Expect(err).NotTo(HaveOccurred())
+
+			details, err := store.FindInstance("instance-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(details).To(Equal(serviceDetails))
+		})
+
+		It("refuses to create an instance twice", func() {
+			store.CreateInstance("instance-id", serviceDetails)
+			err := store.CreateInstance("instance-id", serviceDetails)
+			Expect(err).To(Equal(brokerapi.ErrInstanceAlreadyExists))
+		})
+
+		It("does not find a missing instance", func() {
+			_, err := store.FindInstance("instance-id")
+			Expect(err).To(Equal(brokerapi.ErrInstanceDoesNotExist))
+		})
+
+		It("deletes an instance", func() {
+			store.CreateInstance("instance-id", serviceDetails)
+			Expect(store.DeleteInstance("instance-id")).NotTo(HaveOccurred())
+
+			_, err := store.FindInstance("instance-id")
+			Expect(err).To(Equal(brokerapi.ErrInstanceDoesNotExist))
+			Expect(store.DeleteInstance("instance-id")).To(Equal(brokerapi.ErrInstanceDoesNotExist))
+		})
+	})
+
+	Describe("bindings", func() {
+		It("requires the instance to exist", func() {
+			err := store.CreateBinding("instance-id", "binding-id")
+			Expect(err).To(Equal(brokerapi.ErrInstanceDoesNotExist))
+
+			err = store.DeleteBinding("instance-id", "binding-id")
+			Expect(err).To(Equal(brokerapi.ErrInstanceDoesNotExist))
+		})
+
+		Context("when the instance exists", func() {
+			BeforeEach(func() {
+				store.CreateInstance("instance-id", serviceDetails)
+			})
+
+			It("refuses to create a binding twice", func() {
+				Expect(store.CreateBinding("instance-id", "binding-id")).NotTo(HaveOccurred())
+				Expect(store.CreateBinding("instance-id", "binding-id")).To(Equal(brokerapi.ErrBindingAlreadyExists))
+			})
+
+			It("deletes a binding once", func() {
+				store.CreateBinding("instance-id", "binding-id")
+				Expect(store.DeleteBinding("instance-id", "binding-id")).NotTo(HaveOccurred())
+				Expect(store.DeleteBinding("instance-id", "binding-id")).To(Equal(brokerapi.ErrBindingDoesNotExist))
+			})
+		})
+	})
+})
+
+var _ = Describe("Concurrent binding requests with a store", func() {
+	const concurrency = 50
+
+	var brokerAPI http.Handler
+	var instancePath string
+	var credentials = brokerapi.BrokerCredentials{
+		Username: "username",
+		Password: "password",
+	}
+
+	makeRequest := func(method, path, body string) int {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(method, path, strings.NewReader(body))
+		request.SetBasicAuth(credentials.Username, credentials.Password)
+		brokerAPI.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	makeConcurrentRequests := func(method, path string) map[int]int {
+		var mutex sync.Mutex
+		var wg sync.WaitGroup
+		statusCodes := map[int]int{}
+
+		start := make(chan struct{})
+		for i := 0; i < concurrency; i++ {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+
+				<-start
+				statusCode := makeRequest(method, path, "")
+
+				mutex.Lock()
+				statusCodes[statusCode]++
+				mutex.Unlock()
+			}()
+		}
+		close(start)
+		wg.Wait()
+
+		return statusCodes
+	}
+
+	BeforeEach(func() {
+		fakeServiceBroker := &fakes.FakeServiceBroker{
+			InstanceLimit: 1,
+		}
+		brokerAPI = brokerapi.New(fakeServiceBroker, lagertest.NewTestLogger("broker-api"), credentials, brokerapi.WithStore(brokerapi.NewMemoryStore()))
+
+		instancePath = "/v2/service_instances/" + uniqueInstanceID()
+		Expect(makeRequest("PUT", instancePath, `{"service_id":"service-id","plan_id":"plan-id"}`)).To(Equal(201))
+	})
+
+	It("creates and deletes the binding exactly once", func() {
+		bindingPath := instancePath + "/service_bindings/" + uniqueBindingID()
+
+		Expect(makeConcurrentRequests("PUT", bindingPath)).To(Equal(map[int]int{
+			201: 1,
+			409: concurrency - 1,
+		}))
+
+		Expect(makeConcurrentRequests("DELETE", bindingPath)).To(Equal(map[int]int{
+			200: 1,
+			410: concurrency - 1,
+		}))
+	})
+})
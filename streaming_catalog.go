@@ -0,0 +1,34 @@
+package brokerapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pivotal-golang/lager"
+)
+
+const streamCatalogErrorKey = "stream-catalog"
+
+func respondStreamingCatalog(w http.ResponseWriter, services []Service, logger lager.Logger) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Transfer-Encoding", "chunked")
+	w.WriteHeader(http.StatusOK)
+
+	flusher, _ := w.(http.Flusher)
+	encoder := json.NewEncoder(w)
+
+	w.Write([]byte(`{"services":[`))
+	for i, service := range services {
+		if i > 0 {
+			w.Write([]byte(","))
+		}
+		if err := encoder.Encode(service); err != nil {
+			logger.Error(streamCatalogErrorKey, err)
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	w.Write([]byte("]}\n"))
+}
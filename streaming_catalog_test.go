@@ -0,0 +1,89 @@
+package brokerapi_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/brokerapi"
+	"github.com/pivotal-cf/brokerapi/fakes"
+)
+
+var _ = Describe("Streaming catalog", func() {
+	var fakeServiceBroker *fakes.FakeServiceBroker
+	var credentials = brokerapi.BrokerCredentials{
+		Username: "username",
+		Password: "password",
+	}
+
+	makeCatalogRequest := func(opts ...brokerapi.Option) *httptest.ResponseRecorder {
+		brokerAPI := brokerapi.New(fakeServiceBroker, lagertest.NewTestLogger("broker-api"), credentials, opts...)
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("GET", "/v2/catalog", nil)
+		request.SetBasicAuth(credentials.Username, credentials.Password)
+		brokerAPI.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	BeforeEach(func() {
+		fakeServiceBroker = &fakes.FakeServiceBroker{}
+	})
+
+	It("sets the Transfer-Encoding header", func() {
+		response := makeCatalogRequest(brokerapi.WithStreamingCatalog())
+		Expect(response.Code).To(Equal(200))
+		Expect(response.Header().Get("Transfer-Encoding")).To(Equal("chunked"))
+		Expect(response.Header().Get("Content-Type")).To(Equal("application/json"))
+	})
+
+	It("returns the same catalog as a non-streaming response", func() {
+		streamed := makeCatalogRequest(brokerapi.WithStreamingCatalog())
+		buffered := makeCatalogRequest()
+		Expect(streamed.Body.String()).To(MatchJSON(buffered.Body.String()))
+	})
+
+	It("returns valid JSON for a catalog with many services", func() {
+		services := []brokerapi.Service{}
+		for i := 0; i < 100; i++ {
+			services = append(services, brokerapi.Service{
+				ID:    fmt.Sprintf("service-%d", i),
+				Name:  fmt.Sprintf("service-%d", i),
+				Plans: []brokerapi.ServicePlan{{ID: fmt.Sprintf("plan-%d", i), Name: "default"}},
+			})
+		}
+		fakeServiceBroker.Catalog = services
+
+		streamed := makeCatalogRequest(brokerapi.WithStreamingCatalog())
+		buffered := makeCatalogRequest()
+		Expect(streamed.Body.String()).To(MatchJSON(buffered.Body.String()))
+	})
+
+	It("flushes each service to the client", func() {
+		response := makeCatalogRequest(brokerapi.WithStreamingCatalog())
+		Expect(response.Flushed).To(BeTrue())
+	})
+
+	It("still flushes when the common log is enabled", func() {
+		response := makeCatalogRequest(brokerapi.WithStreamingCatalog(), brokerapi.WithCommonLogFormat(ioutil.Discard))
+		Expect(response.Flushed).To(BeTrue())
+	})
+
+	It("still flushes when an error envelope is configured", func() {
+		response := makeCatalogRequest(brokerapi.WithStreamingCatalog(), brokerapi.WithErrorEnvelope(brokerapi.NestedErrorEnvelope))
+		Expect(response.Flushed).To(BeTrue())
+		Expect(response.Body.String()).To(MatchJSON(makeCatalogRequest().Body.String()))
+	})
+
+	It("returns valid JSON for an empty catalog", func() {
+		fakeServiceBroker.Catalog = []brokerapi.Service{}
+
+		response := makeCatalogRequest(brokerapi.WithStreamingCatalog())
+		Expect(response.Body.String()).To(MatchJSON(`{"services":[]}`))
+	})
+})
@@ -0,0 +1,115 @@
+package brokerapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/pivotal-golang/lager"
+)
+
+const tagLogKey = "tag"
+const untagLogKey = "untag"
+const tagKeyLogKey = "tag-key"
+const invalidTagDetailsErrorKey = "invalid-tag-details"
+const taggingNotSupportedErrorKey = "tagging-not-supported"
+
+var errTaggingNotSupported = errors.New("this broker does not support tagging instances")
+var errTagsMissing = errors.New("tags are required")
+
+type TaggableServiceBroker interface {
+	TagInstance(instanceID string, tags map[string]string) error
+	UntagInstance(instanceID, key string) error
+}
+
+type TagDetails struct {
+	Tags map[string]string `json:"tags"`
+}
+
+func tag(taggableBroker TaggableServiceBroker, router httpRouter, logger lager.Logger, options options) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		instanceID := router.Vars(req)["instance_id"]
+
+		logger := logger.Session(tagLogKey, lager.Data{
+			instanceIDLogKey: instanceID,
+		})
+
+		if taggableBroker == nil {
+			respondTaggingNotSupported(w, logger)
+			return
+		}
+
+		var tagDetails TagDetails
+		if err := json.NewDecoder(req.Body).Decode(&tagDetails); err != nil {
+			logger.Error(invalidTagDetailsErrorKey, err)
+			respond(w, statusUnprocessableEntity, ErrorResponse{
+				Description: err.Error(),
+			})
+			return
+		}
+
+		if len(tagDetails.Tags) == 0 {
+			logger.Error(invalidTagDetailsErrorKey, errTagsMissing)
+			respond(w, http.StatusBadRequest, ErrorResponse{
+				Description: errTagsMissing.Error(),
+			})
+			return
+		}
+
+		if err := taggableBroker.TagInstance(instanceID, tagDetails.Tags); err != nil {
+			respondTaggingError(w, err, logger, options)
+			return
+		}
+
+		respond(w, http.StatusOK, EmptyResponse{})
+	}
+}
+
+func untag(taggableBroker TaggableServiceBroker, router httpRouter, logger lager.Logger, options options) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		vars := router.Vars(req)
+		instanceID := vars["instance_id"]
+
+		logger := logger.Session(untagLogKey, lager.Data{
+			instanceIDLogKey: instanceID,
+			tagKeyLogKey:     vars["key"],
+		})
+
+		if taggableBroker == nil {
+			respondTaggingNotSupported(w, logger)
+			return
+		}
+
+		if err := taggableBroker.UntagInstance(instanceID, vars["key"]); err != nil {
+			respondTaggingError(w, err, logger, options)
+			return
+		}
+
+		respond(w, http.StatusOK, EmptyResponse{})
+	}
+}
+
+func respondTaggingNotSupported(w http.ResponseWriter, logger lager.Logger) {
+	logger.Error(taggingNotSupportedErrorKey, errTaggingNotSupported)
+	respond(w, http.StatusMethodNotAllowed, ErrorResponse{
+		Description: errTaggingNotSupported.Error(),
+	})
+}
+
+func respondTaggingError(w http.ResponseWriter, err error, logger lager.Logger, options options) {
+	switch err {
+	case ErrInstanceDoesNotExist:
+		logBrokerError(logger, options.errorLogLevels, instanceMissingErrorKey, err)
+		respond(w, http.StatusNotFound, ErrorResponse{
+			Description: err.Error(),
+		})
+	case ErrServiceUnavailable:
+		logBrokerError(logger, options.errorLogLevels, serviceUnavailableErrorKey, err)
+		respondServiceUnavailable(w, err)
+	default:
+		logBrokerError(logger, options.errorLogLevels, unknownErrorKey, err)
+		respond(w, http.StatusInternalServerError, ErrorResponse{
+			Description: err.Error(),
+		})
+	}
+}
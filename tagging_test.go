@@ -0,0 +1,92 @@
+package brokerapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/brokerapi"
+	"github.com/pivotal-cf/brokerapi/fakes"
+)
+
+var _ = Describe("Tagging endpoints", func() {
+	var fakeServiceBroker *fakes.FakeTaggableServiceBroker
+	var brokerAPI http.Handler
+	var instanceID string
+	var credentials = brokerapi.BrokerCredentials{
+		Username: "username",
+		Password: "password",
+	}
+
+	makeRequest := func(method, path, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(method, "/v2/service_instances/"+instanceID+path, strings.NewReader(body))
+		request.SetBasicAuth(credentials.Username, credentials.Password)
+		brokerAPI.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	BeforeEach(func() {
+		instanceID = uniqueInstanceID()
+		fakeServiceBroker = &fakes.FakeTaggableServiceBroker{}
+		brokerAPI = brokerapi.New(fakeServiceBroker, lagertest.NewTestLogger("broker-api"), credentials, brokerapi.WithTaggingSupport())
+	})
+
+	It("is not served unless enabled", func() {
+		brokerAPI = brokerapi.New(fakeServiceBroker, lagertest.NewTestLogger("broker-api"), credentials)
+		Expect(makeRequest("POST", "/tags", `{"tags":{"team":"data"}}`).Code).To(Equal(404))
+		Expect(makeRequest("DELETE", "/tags/team", "").Code).To(Equal(404))
+	})
+
+	It("returns a 405 when the broker cannot tag instances", func() {
+		brokerAPI = brokerapi.New(&fakes.FakeServiceBroker{}, lagertest.NewTestLogger("broker-api"), credentials, brokerapi.WithTaggingSupport())
+		response := makeRequest("POST", "/tags", `{"tags":{"team":"data"}}`)
+		Expect(response.Code).To(Equal(405))
+		Expect(response.Body.String()).To(MatchJSON(`{"description":"this broker does not support tagging instances"}`))
+	})
+
+	Describe("tagging", func() {
+		It("forwards the tags to the broker and returns a 200", func() {
+			response := makeRequest("POST", "/tags", `{"tags":{"team":"data","cost-center":"42"}}`)
+			Expect(response.Code).To(Equal(200))
+			Expect(response.Body.String()).To(MatchJSON(`{}`))
+			Expect(fakeServiceBroker.TaggedInstanceIDs).To(Equal([]string{instanceID}))
+			Expect(fakeServiceBroker.Tags).To(Equal(map[string]string{
+				"team":        "data",
+				"cost-center": "42",
+			}))
+		})
+
+		It("returns a 400 when no tags are given", func() {
+			response := makeRequest("POST", "/tags", `{"tags":{}}`)
+			Expect(response.Code).To(Equal(400))
+			Expect(fakeServiceBroker.BrokerCalled).To(BeFalse())
+		})
+
+		It("returns a 404 when the instance does not exist", func() {
+			fakeServiceBroker.TagError = brokerapi.ErrInstanceDoesNotExist
+			response := makeRequest("POST", "/tags", `{"tags":{"team":"data"}}`)
+			Expect(response.Code).To(Equal(404))
+		})
+	})
+
+	Describe("removing a tag", func() {
+		It("forwards the key to the broker and returns a 200", func() {
+			response := makeRequest("DELETE", "/tags/team", "")
+			Expect(response.Code).To(Equal(200))
+			Expect(fakeServiceBroker.UntaggedInstanceIDs).To(Equal([]string{instanceID}))
+			Expect(fakeServiceBroker.RemovedTagKeys).To(Equal([]string{"team"}))
+		})
+
+		It("returns a 404 when the instance does not exist", func() {
+			fakeServiceBroker.TagError = brokerapi.ErrInstanceDoesNotExist
+			response := makeRequest("DELETE", "/tags/team", "")
+			Expect(response.Code).To(Equal(404))
+		})
+	})
+})
@@ -0,0 +1,120 @@
+package testhelpers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pivotal-cf/brokerapi"
+	"github.com/pivotal-golang/lager"
+)
+
+const (
+	osbServiceID = "osb-service-id"
+	osbPlanID    = "osb-plan-id"
+)
+
+var errUnexpected = errors.New("unexpected broker error")
+
+var osbCredentials = brokerapi.BrokerCredentials{
+	Username: "username",
+	Password: "password",
+}
+
+type osbStatusCode struct {
+	operation string
+	err       error
+	status    int
+}
+
+var osbStatusCodes = []osbStatusCode{
+	{"provision", brokerapi.ErrInstanceAlreadyExists, http.StatusConflict},
+	{"provision", brokerapi.ErrInstanceLimitMet, http.StatusInternalServerError},
+	{"provision", brokerapi.ErrQuotaExceeded, 422},
+	{"provision", brokerapi.ErrConcurrentOperation, 422},
+	{"provision", brokerapi.ErrServiceUnavailable, http.StatusServiceUnavailable},
+	{"provision", errUnexpected, http.StatusInternalServerError},
+
+	{"deprovision", brokerapi.ErrInstanceDoesNotExist, http.StatusGone},
+	{"deprovision", brokerapi.ErrInstanceGone, http.StatusGone},
+	{"deprovision", brokerapi.ErrConcurrentOperation, 422},
+	{"deprovision", brokerapi.ErrServiceUnavailable, http.StatusServiceUnavailable},
+	{"deprovision", errUnexpected, http.StatusInternalServerError},
+
+	{"bind", brokerapi.ErrInstanceDoesNotExist, http.StatusNotFound},
+	{"bind", brokerapi.ErrBindingAlreadyExists, http.StatusConflict},
+	{"bind", brokerapi.ErrConcurrentOperation, 422},
+	{"bind", brokerapi.ErrServiceUnavailable, http.StatusServiceUnavailable},
+	{"bind", errUnexpected, http.StatusInternalServerError},
+
+	{"unbind", brokerapi.ErrInstanceDoesNotExist, http.StatusNotFound},
+	{"unbind", brokerapi.ErrBindingDoesNotExist, http.StatusGone},
+	{"unbind", brokerapi.ErrConcurrentOperation, 422},
+	{"unbind", brokerapi.ErrServiceUnavailable, http.StatusServiceUnavailable},
+	{"unbind", errUnexpected, http.StatusInternalServerError},
+}
+
+type errorBroker struct {
+	err error
+}
+
+func (broker errorBroker) Services() []brokerapi.Service {
+	return []brokerapi.Service{
+		{
+			ID:       osbServiceID,
+			Name:     "osb-service",
+			Bindable: true,
+			Plans:    []brokerapi.ServicePlan{{ID: osbPlanID, Name: "osb-plan"}},
+		},
+	}
+}
+
+func (broker errorBroker) Provision(instanceID string, serviceDetails brokerapi.ServiceDetails) error {
+	return broker.err
+}
+
+func (broker errorBroker) Deprovision(instanceID string) error {
+	return broker.err
+}
+
+func (broker errorBroker) Bind(instanceID, bindingID string) (interface{}, error) {
+	return nil, broker.err
+}
+
+func (broker errorBroker) Unbind(instanceID, bindingID string) error {
+	return broker.err
+}
+
+func AssertOSBStatusCodes(t *testing.T, opts ...brokerapi.Option) {
+	for _, expected := range osbStatusCodes {
+		handler := brokerapi.New(errorBroker{err: expected.err}, lager.NewLogger("osb-status-codes"), osbCredentials, opts...)
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, osbRequest(expected.operation))
+
+		if recorder.Code != expected.status {
+			t.Errorf("%s returning %q: expected status %d, got %d", expected.operation, expected.err, expected.status, recorder.Code)
+		}
+	}
+}
+
+func osbRequest(operation string) *http.Request {
+	body := `{"service_id":"` + osbServiceID + `","plan_id":"` + osbPlanID + `"}`
+	query := "?service_id=" + osbServiceID + "&plan_id=" + osbPlanID
+
+	var request *http.Request
+	switch operation {
+	case "provision":
+		request, _ = http.NewRequest("PUT", "/v2/service_instances/instance-id", strings.NewReader(body))
+	case "deprovision":
+		request, _ = http.NewRequest("DELETE", "/v2/service_instances/instance-id"+query, nil)
+	case "bind":
+		request, _ = http.NewRequest("PUT", "/v2/service_instances/instance-id/service_bindings/binding-id", strings.NewReader(body))
+	case "unbind":
+		request, _ = http.NewRequest("DELETE", "/v2/service_instances/instance-id/service_bindings/binding-id"+query, nil)
+	}
+	request.SetBasicAuth(osbCredentials.Username, osbCredentials.Password)
+	return request
+}
@@ -0,0 +1,16 @@
+package testhelpers_test
+
+import (
+	"testing"
+
+	"github.com/pivotal-cf/brokerapi"
+	"github.com/pivotal-cf/brokerapi/testhelpers"
+)
+
+func TestOSBStatusCodes(t *testing.T) {
+	testhelpers.AssertOSBStatusCodes(t)
+}
+
+func TestOSBStatusCodesWithOptions(t *testing.T) {
+	testhelpers.AssertOSBStatusCodes(t, brokerapi.WithInstanceLocking(), brokerapi.WithEventLog(brokerapi.NewMemoryEventLog()))
+}
@@ -0,0 +1,128 @@
+package testhelpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pivotal-cf/brokerapi"
+	"github.com/pivotal-golang/lager"
+)
+
+type FixtureMismatchError struct {
+	Fixture  string
+	Expected string
+	Actual   string
+}
+
+func (err FixtureMismatchError) Error() string {
+	return fmt.Sprintf("response does not match fixture %s\nexpected: %s\nactual: %s", err.Fixture, err.Expected, err.Actual)
+}
+
+type RecordingBroker struct {
+	handler    http.Handler
+	fixtureDir string
+
+	mutex      sync.Mutex
+	mismatches []error
+}
+
+func NewRecordingBroker(inner brokerapi.ServiceBroker, fixtureDir string, opts ...brokerapi.Option) *RecordingBroker {
+	return &RecordingBroker{
+		handler:    brokerapi.New(inner, lager.NewLogger("recording-broker"), osbCredentials, opts...),
+		fixtureDir: fixtureDir,
+	}
+}
+
+func (broker *RecordingBroker) Catalog() error {
+	return broker.record("catalog.json", "GET", "/v2/catalog", nil)
+}
+
+func (broker *RecordingBroker) Provision(instanceID string, serviceDetails brokerapi.ServiceDetails) error {
+	return broker.record("provision.json", "PUT", "/v2/service_instances/"+instanceID, serviceDetails)
+}
+
+func (broker *RecordingBroker) Deprovision(instanceID string) error {
+	return broker.record("deprovision.json", "DELETE", "/v2/service_instances/"+instanceID, nil)
+}
+
+func (broker *RecordingBroker) Bind(instanceID, bindingID string, bindDetails brokerapi.BindDetails) error {
+	return broker.record("bind.json", "PUT", bindingPath(instanceID, bindingID), bindDetails)
+}
+
+func (broker *RecordingBroker) Unbind(instanceID, bindingID string) error {
+	return broker.record("unbind.json", "DELETE", bindingPath(instanceID, bindingID), nil)
+}
+
+func (broker *RecordingBroker) Mismatches() []error {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+
+	return append([]error{}, broker.mismatches...)
+}
+
+func (broker *RecordingBroker) record(fixture, method, path string, details interface{}) error {
+	var body io.Reader
+	if details != nil {
+		data, err := json.Marshal(details)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	request, err := http.NewRequest(method, path, body)
+	if err != nil {
+		return err
+	}
+	request.SetBasicAuth(osbCredentials.Username, osbCredentials.Password)
+
+	recorder := httptest.NewRecorder()
+	broker.handler.ServeHTTP(recorder, request)
+	if recorder.Code < 200 || recorder.Code > 299 {
+		return fmt.Errorf("%s %s returned status %d: %s", method, path, recorder.Code, recorder.Body.String())
+	}
+
+	return broker.check(fixture, recorder.Body.Bytes())
+}
+
+func (broker *RecordingBroker) check(fixture string, actual []byte) error {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+
+	err := compareFixture(filepath.Join(broker.fixtureDir, fixture), actual)
+	if err != nil {
+		broker.mismatches = append(broker.mismatches, err)
+	}
+	return err
+}
+
+func compareFixture(path string, actual []byte) error {
+	expected, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return ioutil.WriteFile(path, actual, 0644)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(expected, actual) {
+		return FixtureMismatchError{
+			Fixture:  path,
+			Expected: string(expected),
+			Actual:   string(actual),
+		}
+	}
+	return nil
+}
+
+func bindingPath(instanceID, bindingID string) string {
+	return "/v2/service_instances/" + instanceID + "/service_bindings/" + bindingID
+}
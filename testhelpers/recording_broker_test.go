@@ -0,0 +1,105 @@
+package testhelpers_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/brokerapi"
+	"github.com/pivotal-cf/brokerapi/fakes"
+	"github.com/pivotal-cf/brokerapi/testhelpers"
+	"github.com/pivotal-golang/lager"
+)
+
+var _ = Describe("RecordingBroker", func() {
+	var fixtureDir string
+	var innerBroker *fakes.FakeServiceBroker
+	var recordingBroker *testhelpers.RecordingBroker
+
+	bindDetails := brokerapi.BindDetails{ServiceID: "service-id", PlanID: "plan-id"}
+
+	BeforeEach(func() {
+		var err error
+		fixtureDir, err = ioutil.TempDir("", "recording-broker")
+		Expect(err).NotTo(HaveOccurred())
+
+		innerBroker = &fakes.FakeServiceBroker{InstanceLimit: 3}
+		recordingBroker = testhelpers.NewRecordingBroker(innerBroker, fixtureDir)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(fixtureDir)
+	})
+
+	It("writes the handler's response body on first call", func() {
+		Expect(recordingBroker.Catalog()).NotTo(HaveOccurred())
+
+		handler := brokerapi.New(innerBroker, lager.NewLogger("test"), brokerapi.BrokerCredentials{Username: "username", Password: "password"})
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("GET", "/v2/catalog", nil)
+		request.SetBasicAuth("username", "password")
+		handler.ServeHTTP(recorder, request)
+
+		fixture, err := ioutil.ReadFile(filepath.Join(fixtureDir, "catalog.json"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fixture).To(Equal(recorder.Body.Bytes()))
+		Expect(recordingBroker.Mismatches()).To(BeEmpty())
+	})
+
+	It("passes when the response is unchanged", func() {
+		Expect(recordingBroker.Catalog()).NotTo(HaveOccurred())
+		Expect(recordingBroker.Catalog()).NotTo(HaveOccurred())
+
+		Expect(recordingBroker.Bind("instance-id", "binding-id", bindDetails)).NotTo(HaveOccurred())
+		Expect(recordingBroker.Bind("instance-id", "other-binding-id", bindDetails)).NotTo(HaveOccurred())
+
+		Expect(recordingBroker.Mismatches()).To(BeEmpty())
+	})
+
+	It("fails when the catalog changes", func() {
+		Expect(recordingBroker.Catalog()).NotTo(HaveOccurred())
+
+		innerBroker.Catalog = []brokerapi.Service{{ID: "other-service"}}
+		err := recordingBroker.Catalog()
+		Expect(err).To(BeAssignableToTypeOf(testhelpers.FixtureMismatchError{}))
+
+		mismatches := recordingBroker.Mismatches()
+		Expect(mismatches).To(HaveLen(1))
+		mismatch, ok := mismatches[0].(testhelpers.FixtureMismatchError)
+		Expect(ok).To(BeTrue())
+		Expect(mismatch.Fixture).To(Equal(filepath.Join(fixtureDir, "catalog.json")))
+	})
+
+	It("fails when the format of the response changes", func() {
+		err := ioutil.WriteFile(filepath.Join(fixtureDir, "provision.json"), []byte("{ }\n"), 0644)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = recordingBroker.Provision("instance-id", brokerapi.ServiceDetails{ID: "service-id", PlanID: "plan-id"})
+		Expect(err).To(BeAssignableToTypeOf(testhelpers.FixtureMismatchError{}))
+		Expect(recordingBroker.Mismatches()).To(HaveLen(1))
+	})
+
+	It("returns an error when the binding response changes", func() {
+		err := ioutil.WriteFile(filepath.Join(fixtureDir, "bind.json"), []byte(`{"credentials":{"host":"old-host"}}`), 0644)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = recordingBroker.Bind("instance-id", "binding-id", bindDetails)
+		Expect(err).To(BeAssignableToTypeOf(testhelpers.FixtureMismatchError{}))
+		Expect(recordingBroker.Mismatches()).To(HaveLen(1))
+	})
+
+	It("does not record failed calls", func() {
+		innerBroker.ProvisionError = brokerapi.ErrInstanceLimitMet
+
+		err := recordingBroker.Provision("instance-id", brokerapi.ServiceDetails{ID: "service-id", PlanID: "plan-id"})
+		Expect(err).To(HaveOccurred())
+
+		_, err = os.Stat(filepath.Join(fixtureDir, "provision.json"))
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,128 @@
+package brokerapi
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pivotal-golang/lager"
+)
+
+const webhookSignatureHeader = "X-Broker-Signature"
+const webhookErrorKey = "webhook-failed"
+const emitEventErrorKey = "emit-event-failed"
+
+const (
+	WebhookEventProvisioned   = "service_instance.provisioned"
+	WebhookEventDeprovisioned = "service_instance.deprovisioned"
+)
+
+type WebhookEvent struct {
+	EventType        string `json:"event_type"`
+	InstanceID       string `json:"instance_id"`
+	BindingID        string `json:"binding_id,omitempty"`
+	ServiceID        string `json:"service_id"`
+	PlanID           string `json:"plan_id"`
+	OrganizationGUID string `json:"org_guid"`
+	SpaceGUID        string `json:"space_guid"`
+	Timestamp        string `json:"timestamp"`
+}
+
+type EventEmitter interface {
+	Emit(event WebhookEvent) error
+}
+
+type eventNotifier struct {
+	emitters []EventEmitter
+	logger   lager.Logger
+}
+
+func newEventNotifier(options options, logger lager.Logger) *eventNotifier {
+	emitters := options.eventEmitters
+	if options.webhookURL != "" {
+		emitters = append([]EventEmitter{newWebhookEmitter(options.webhookURL, options.webhookSecret)}, emitters...)
+	}
+
+	if len(emitters) == 0 {
+		return nil
+	}
+
+	return &eventNotifier{
+		emitters: emitters,
+		logger:   logger,
+	}
+}
+
+func (notifier *eventNotifier) notify(event WebhookEvent) {
+	if notifier == nil {
+		return
+	}
+
+	event.Timestamp = time.Now().UTC().Format(time.RFC3339)
+
+	for _, emitter := range notifier.emitters {
+		go func(emitter EventEmitter) {
+			if err := emitter.Emit(event); err != nil {
+				errorKey := emitEventErrorKey
+				if _, ok := emitter.(*webhookEmitter); ok {
+					errorKey = webhookErrorKey
+				}
+
+				notifier.logger.Error(errorKey, err, lager.Data{
+					"event-type":     event.EventType,
+					instanceIDLogKey: event.InstanceID,
+				})
+			}
+		}(emitter)
+	}
+}
+
+type webhookEmitter struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+func newWebhookEmitter(url string, secret []byte) *webhookEmitter {
+	return &webhookEmitter{
+		url:    url,
+		secret: secret,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (emitter *webhookEmitter) Emit(event WebhookEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest("POST", emitter.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(webhookSignatureHeader, SignWebhookPayload(body, emitter.secret))
+
+	response, err := emitter.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", response.StatusCode)
+	}
+	return nil
+}
+
+func SignWebhookPayload(payload, secret []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
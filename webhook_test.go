@@ -0,0 +1,189 @@
+package brokerapi_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/brokerapi"
+	"github.com/pivotal-cf/brokerapi/fakes"
+)
+
+type webhookRequest struct {
+	signature string
+	body      []byte
+}
+
+var _ = Describe("Webhook notifications", func() {
+	var fakeServiceBroker *fakes.FakeServiceBroker
+	var brokerAPI http.Handler
+	var webhookServer *httptest.Server
+	var webhookRequests chan webhookRequest
+	var instanceID string
+	var secret = []byte("webhook-secret")
+	var credentials = brokerapi.BrokerCredentials{
+		Username: "username",
+		Password: "password",
+	}
+
+	makeRequest := func(method, path, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(method, path, strings.NewReader(body))
+		request.SetBasicAuth(credentials.Username, credentials.Password)
+		brokerAPI.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	provisionInstance := func() *httptest.ResponseRecorder {
+		return makeRequest("PUT", "/v2/service_instances/"+instanceID, `{
+			"service_id": "service-id",
+			"plan_id": "plan-id",
+			"organization_guid": "organization-guid",
+			"space_guid": "space-guid"
+		}`)
+	}
+
+	receiveEvent := func() (brokerapi.WebhookEvent, webhookRequest) {
+		var request webhookRequest
+		Eventually(webhookRequests, 5*time.Second).Should(Receive(&request))
+
+		var event brokerapi.WebhookEvent
+		err := json.Unmarshal(request.body, &event)
+		Expect(err).NotTo(HaveOccurred())
+		return event, request
+	}
+
+	BeforeEach(func() {
+		instanceID = uniqueInstanceID()
+		webhookRequests = make(chan webhookRequest, 10)
+		webhookServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			body, _ := ioutil.ReadAll(req.Body)
+			webhookRequests <- webhookRequest{
+				signature: req.Header.Get("X-Broker-Signature"),
+				body:      body,
+			}
+		}))
+
+		fakeServiceBroker = &fakes.FakeServiceBroker{
+			InstanceLimit: 3,
+		}
+		brokerAPI = brokerapi.New(fakeServiceBroker, lagertest.NewTestLogger("broker-api"), credentials, brokerapi.WithWebhookNotifier(webhookServer.URL, secret))
+	})
+
+	AfterEach(func() {
+		webhookServer.Close()
+	})
+
+	It("posts an event when an instance is provisioned", func() {
+		Expect(provisionInstance().Code).To(Equal(201))
+
+		event, _ := receiveEvent()
+		Expect(event.EventType).To(Equal("service_instance.provisioned"))
+		Expect(event.InstanceID).To(Equal(instanceID))
+		Expect(event.ServiceID).To(Equal("service-id"))
+		Expect(event.PlanID).To(Equal("plan-id"))
+		Expect(event.OrganizationGUID).To(Equal("organization-guid"))
+		Expect(event.SpaceGUID).To(Equal("space-guid"))
+
+		timestamp, err := time.Parse(time.RFC3339, event.Timestamp)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(timestamp).To(BeTemporally("~", time.Now(), time.Minute))
+	})
+
+	It("posts an event when an instance is deprovisioned", func() {
+		provisionInstance()
+		receiveEvent()
+
+		response := makeRequest("DELETE", "/v2/service_instances/"+instanceID+"?service_id=service-id&plan_id=plan-id", "")
+		Expect(response.Code).To(Equal(200))
+
+		event, _ := receiveEvent()
+		Expect(event.EventType).To(Equal("service_instance.deprovisioned"))
+		Expect(event.InstanceID).To(Equal(instanceID))
+		Expect(event.ServiceID).To(Equal("service-id"))
+		Expect(event.PlanID).To(Equal("plan-id"))
+	})
+
+	It("sends the payload using the documented keys", func() {
+		provisionInstance()
+
+		_, request := receiveEvent()
+		var payload map[string]interface{}
+		err := json.Unmarshal(request.body, &payload)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(payload).To(HaveKey("event_type"))
+		Expect(payload).To(HaveKey("instance_id"))
+		Expect(payload).To(HaveKey("service_id"))
+		Expect(payload).To(HaveKey("plan_id"))
+		Expect(payload).To(HaveKey("org_guid"))
+		Expect(payload).To(HaveKey("space_guid"))
+		Expect(payload).To(HaveKey("timestamp"))
+	})
+
+	It("signs the payload with the secret", func() {
+		provisionInstance()
+
+		_, request := receiveEvent()
+		Expect(strings.HasPrefix(request.signature, "sha256=")).To(BeTrue())
+		Expect(request.signature).To(Equal(brokerapi.SignWebhookPayload(request.body, secret)))
+		Expect(request.signature).NotTo(Equal(brokerapi.SignWebhookPayload(request.body, []byte("other-secret"))))
+	})
+
+	It("does not post an event when provisioning fails", func() {
+		fakeServiceBroker.ProvisionError = brokerapi.ErrInstanceLimitMet
+		Expect(provisionInstance().Code).To(Equal(500))
+
+		Consistently(webhookRequests, 200*time.Millisecond).ShouldNot(Receive())
+	})
+
+	It("does not delay the response while the webhook is in flight", func() {
+		received := make(chan struct{}, 1)
+		release := make(chan struct{})
+		blockingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			received <- struct{}{}
+			<-release
+		}))
+		defer blockingServer.Close()
+		defer close(release)
+
+		brokerAPI = brokerapi.New(fakeServiceBroker, lagertest.NewTestLogger("broker-api"), credentials, brokerapi.WithWebhookNotifier(blockingServer.URL, secret))
+
+		responseCodes := make(chan int, 1)
+		go func() {
+			defer GinkgoRecover()
+			responseCodes <- provisionInstance().Code
+		}()
+
+		Eventually(responseCodes, 5*time.Second).Should(Receive(Equal(201)))
+		Eventually(received, 5*time.Second).Should(Receive())
+	})
+
+	It("does not fail the response when the webhook is unreachable", func() {
+		webhookServer.Close()
+		Expect(provisionInstance().Code).To(Equal(201))
+	})
+
+	It("logs failed deliveries under webhook-failed", func() {
+		logger := lagertest.NewTestLogger("broker-api")
+		brokerAPI = brokerapi.New(fakeServiceBroker, logger, credentials, brokerapi.WithWebhookNotifier(webhookServer.URL, secret))
+		webhookServer.Close()
+
+		provisionInstance()
+
+		Eventually(func() []string {
+			messages := []string{}
+			for _, log := range logger.Logs() {
+				messages = append(messages, log.Message)
+			}
+			return messages
+		}, 5*time.Second).Should(ContainElement("broker-api.webhook-failed"))
+	})
+})